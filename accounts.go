@@ -1,9 +1,8 @@
 package tango
 
 import (
+	"context"
 	"encoding/json"
-
-	"github.com/go-resty/resty/v2"
 )
 
 type Account struct {
@@ -11,10 +10,26 @@ type Account struct {
 	AccountNumber     string `json:"accountNumber"`
 	DisplayName       string `json:"displayName"`
 	CurrencyCode      string `json:"currencyCode"`
-	CurrentBalance    int    `json:"currentBalance"`
-	CreatedAt         string `json:"createdAt"`
-	Status            string `json:"status"`
-	ContactEmail      string `json:"contactEmail"`
+	// CurrentBalance is Balance without the cents.
+	//
+	// Deprecated: use Balance.
+	CurrentBalance int `json:"-"`
+	// Balance is the account's current balance in CurrencyCode.
+	Balance      float64 `json:"currentBalance"`
+	CreatedAt    string  `json:"createdAt"`
+	Status       string  `json:"status"`
+	ContactEmail string  `json:"contactEmail"`
+}
+
+// UnmarshalJSON fills CurrentBalance from Balance, since Tango reports
+// balances with cents that an int cannot hold.
+func (a *Account) UnmarshalJSON(data []byte) error {
+	type account Account
+	if err := json.Unmarshal(data, (*account)(a)); err != nil {
+		return err
+	}
+	a.CurrentBalance = int(a.Balance)
+	return nil
 }
 
 /*
@@ -23,13 +38,12 @@ https://developers.tangocard.com/reference/getaccount-1
 */
 func (c *TangoClient) GetAccountInfo(accountID string) (Account, error) {
 	// https://integration-api.tangocard.com/raas/v2/accounts/{accountIdentifier}
-	url := ApiURL + "/accounts/" + accountID
+	url := c.apiURL() + "/accounts/" + accountID
 
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		Get(url)
 
 	if err != nil {
@@ -47,3 +61,121 @@ func (c *TangoClient) GetAccountInfo(accountID string) (Account, error) {
 
 	return responseData, nil
 }
+
+/*
+Get a list of every Account on the Tango Platform, across all Customers.
+https://developers.tangocard.com/reference/listaccounts-1
+*/
+func (c *TangoClient) ListAccounts(ctx context.Context) ([]Account, error) {
+	url := c.apiURL() + "/accounts"
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		Get(url)
+
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureSuccessStatus(resp, "list accounts"); err != nil {
+		return nil, err
+	}
+
+	var responseData []Account
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return nil, err
+	}
+
+	return responseData, nil
+}
+
+// PlatformOverview is every Customer on the platform with its Accounts.
+type PlatformOverview struct {
+	Customers []CustomerOverview
+	// Unassigned holds accounts that no customer lists.
+	Unassigned []Account
+}
+
+// CustomerOverview is a Customer with the full details, including balances,
+// of each of its Accounts.
+type CustomerOverview struct {
+	Customer Customer
+	Accounts []Account
+}
+
+// Balances returns the customer's total balance in each currency.
+func (o CustomerOverview) Balances() map[string]float64 {
+	totals := make(map[string]float64)
+	for _, account := range o.Accounts {
+		totals[account.CurrencyCode] += account.Balance
+	}
+	return totals
+}
+
+/*
+GetPlatformOverview builds the customer → accounts tree for the platform, with
+balances. It lists the customers and all accounts, then looks up the accounts
+of any customer whose listing did not include them.
+*/
+func (c *TangoClient) GetPlatformOverview(ctx context.Context) (PlatformOverview, error) {
+	accounts, err := c.ListAccounts(ctx)
+	if err != nil {
+		return PlatformOverview{}, err
+	}
+	byID := make(map[string]Account, len(accounts))
+	for _, account := range accounts {
+		byID[account.AccountIdentifier] = account
+	}
+
+	if err := ctx.Err(); err != nil {
+		return PlatformOverview{}, err
+	}
+	customers, err := c.GetCustomers()
+	if err != nil {
+		return PlatformOverview{}, err
+	}
+
+	var overview PlatformOverview
+	assigned := make(map[string]bool, len(accounts))
+	for _, customer := range customers {
+		customerAccounts := customer.Accounts
+		if len(customerAccounts) == 0 {
+			if err := ctx.Err(); err != nil {
+				return PlatformOverview{}, err
+			}
+			customerAccounts, err = c.GetCustomerAccounts(customer.CustomerIdentifier)
+			if err != nil {
+				return PlatformOverview{}, err
+			}
+		}
+
+		entry := CustomerOverview{Customer: customer}
+		for _, userAccount := range customerAccounts {
+			account, ok := byID[userAccount.AccountIdentifier]
+			if !ok {
+				// Created since the account list was fetched
+				account = Account{
+					AccountIdentifier: userAccount.AccountIdentifier,
+					AccountNumber:     userAccount.AccountNumber,
+					DisplayName:       userAccount.DisplayName,
+					CreatedAt:         userAccount.CreatedAt,
+					Status:            userAccount.Status,
+				}
+			}
+			assigned[account.AccountIdentifier] = true
+			entry.Accounts = append(entry.Accounts, account)
+		}
+		overview.Customers = append(overview.Customers, entry)
+	}
+
+	for _, account := range accounts {
+		if !assigned[account.AccountIdentifier] {
+			overview.Unassigned = append(overview.Unassigned, account)
+		}
+	}
+
+	return overview, nil
+}
@@ -0,0 +1,94 @@
+package tango
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestListAccounts(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/accounts" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"accountIdentifier":"a1","currencyCode":"USD","currentBalance":100.75},{"accountIdentifier":"a2","currencyCode":"EUR","currentBalance":50}]`))
+	})
+	client := &TangoClient{Token: "token"}
+
+	accounts, err := client.ListAccounts(context.Background())
+	if err != nil || len(accounts) != 2 || accounts[1].CurrencyCode != "EUR" || accounts[0].Balance != 100.75 {
+		t.Fatalf("unexpected accounts: %+v, %v", accounts, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := client.ListAccounts(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected a cancelled request, got %v", err)
+	}
+}
+
+func TestGetAccountInfo_FractionalBalance(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"accountIdentifier":"ops","currencyCode":"USD","currentBalance":1234.56}`))
+	})
+	client := &TangoClient{Token: "token"}
+
+	account, err := client.GetAccountInfo("ops")
+	if err != nil {
+		t.Fatalf("get account: %v", err)
+	}
+	if account.Balance != 1234.56 || account.CurrentBalance != 1234 {
+		t.Fatalf("unexpected balance %v (%d)", account.Balance, account.CurrentBalance)
+	}
+}
+
+func TestGetPlatformOverview(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/accounts":
+			_, _ = w.Write([]byte(`[
+				{"accountIdentifier":"mkt","currencyCode":"USD","currentBalance":100.25},
+				{"accountIdentifier":"sales","currencyCode":"USD","currentBalance":250.5},
+				{"accountIdentifier":"eu","currencyCode":"EUR","currentBalance":40},
+				{"accountIdentifier":"orphan","currencyCode":"USD","currentBalance":5}
+			]`))
+		case "/customers":
+			_, _ = w.Write([]byte(`[
+				{"customerIdentifier":"acme","accounts":[{"accountIdentifier":"mkt"},{"accountIdentifier":"sales"},{"accountIdentifier":"new","displayName":"New"}]},
+				{"customerIdentifier":"globex"}
+			]`))
+		case "/customers/globex/accounts":
+			_, _ = w.Write([]byte(`[{"accountIdentifier":"eu"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client := &TangoClient{Token: "token"}
+
+	overview, err := client.GetPlatformOverview(context.Background())
+	if err != nil {
+		t.Fatalf("overview: %v", err)
+	}
+	if len(overview.Customers) != 2 {
+		t.Fatalf("unexpected customers: %+v", overview.Customers)
+	}
+
+	acme := overview.Customers[0]
+	if len(acme.Accounts) != 3 || acme.Accounts[1].Balance != 250.5 || acme.Accounts[2].DisplayName != "New" {
+		t.Fatalf("unexpected acme accounts: %+v", acme.Accounts)
+	}
+	if balances := acme.Balances(); balances["USD"] != 350.75 {
+		t.Fatalf("unexpected acme balances: %v", balances)
+	}
+
+	globex := overview.Customers[1]
+	if len(globex.Accounts) != 1 || globex.Accounts[0].CurrencyCode != "EUR" || globex.Accounts[0].Balance != 40 {
+		t.Fatalf("expected globex accounts to be looked up, got %+v", globex.Accounts)
+	}
+
+	if len(overview.Unassigned) != 1 || overview.Unassigned[0].AccountIdentifier != "orphan" {
+		t.Fatalf("unexpected unassigned accounts: %+v", overview.Unassigned)
+	}
+}
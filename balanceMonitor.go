@@ -0,0 +1,262 @@
+package tango
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// DefaultBalancePollInterval is how often a BalanceMonitor checks balances
+// when no Interval is set.
+const DefaultBalancePollInterval = 5 * time.Minute
+
+// DefaultSpendWindow is how much balance history a BalanceMonitor uses to
+// estimate spend when no SpendWindow is set.
+const DefaultSpendWindow = 7 * 24 * time.Hour
+
+// AccountInfoGetter is implemented by *TangoClient.
+type AccountInfoGetter interface {
+	GetAccountInfo(accountID string) (Account, error)
+}
+
+/*
+BalanceThreshold is a balance level a BalanceMonitor watches. It fires once
+when the balance drops below Amount and is re-armed only after the balance
+climbs back to at least Amount + Hysteresis, so a balance hovering around the
+threshold does not fire over and over.
+*/
+type BalanceThreshold struct {
+	Amount     float64
+	Hysteresis float64
+}
+
+// BalanceEvent describes a threshold crossing.
+type BalanceEvent struct {
+	AccountIdentifier string
+	Threshold         BalanceThreshold
+	Balance           float64
+	CurrencyCode      string
+	// Runway is the estimated time until the balance runs out at the recent
+	// rate of spend, or zero if there is not enough history to tell.
+	Runway time.Duration
+	Time   time.Time
+}
+
+/*
+BalanceMonitor polls the balances of a set of accounts and calls OnBelow when
+one drops below a threshold and OnRecovered when it climbs back above it
+(plus hysteresis). The first poll counts as a crossing for every threshold the
+balance is already below.
+
+Callbacks run on the polling goroutine, so a slow callback delays the next
+poll; hand long work, such as funding the account, off to another goroutine.
+*/
+type BalanceMonitor struct {
+	Client     AccountInfoGetter
+	Accounts   []string
+	Thresholds []BalanceThreshold
+
+	OnBelow     func(BalanceEvent)
+	OnRecovered func(BalanceEvent)
+	// OnError is called when an account's balance cannot be fetched.
+	OnError func(accountIdentifier string, err error)
+
+	// Interval is the time between polls. Zero uses DefaultBalancePollInterval.
+	Interval time.Duration
+	// SpendWindow is how far back spend is averaged for the runway estimate.
+	// Zero uses DefaultSpendWindow.
+	SpendWindow time.Duration
+	// Now is the monitor's clock. Nil uses time.Now.
+	Now func() time.Time
+
+	mu       sync.Mutex
+	accounts map[string]*monitoredAccount
+}
+
+type monitoredAccount struct {
+	balance  float64
+	currency string
+	updated  time.Time
+	history  []balanceSample
+	below    []bool
+}
+
+type balanceSample struct {
+	balance float64
+	at      time.Time
+}
+
+// Run polls every Interval until ctx is cancelled, then returns nil.
+func (m *BalanceMonitor) Run(ctx context.Context) error {
+	interval := m.Interval
+	if interval <= 0 {
+		interval = DefaultBalancePollInterval
+	}
+
+	for {
+		m.Poll()
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// Poll checks every account once and fires any callbacks that are due.
+func (m *BalanceMonitor) Poll() {
+	for _, accountID := range m.Accounts {
+		account, err := m.Client.GetAccountInfo(accountID)
+		if err != nil {
+			if m.OnError != nil {
+				m.OnError(accountID, err)
+			}
+			continue
+		}
+		m.record(accountID, account)
+	}
+}
+
+// record stores a new balance and fires the callbacks for any crossings.
+func (m *BalanceMonitor) record(accountID string, account Account) {
+	now := m.now()
+	balance := account.Balance
+
+	m.mu.Lock()
+	if m.accounts == nil {
+		m.accounts = make(map[string]*monitoredAccount)
+	}
+	state, ok := m.accounts[accountID]
+	if !ok {
+		state = &monitoredAccount{}
+		m.accounts[accountID] = state
+	}
+	for len(state.below) < len(m.Thresholds) {
+		state.below = append(state.below, false)
+	}
+
+	state.balance = balance
+	state.currency = account.CurrencyCode
+	state.updated = now
+	state.history = append(state.history, balanceSample{balance: balance, at: now})
+	state.history = trimHistory(state.history, now.Add(-m.spendWindow()))
+	runway, _ := state.runway()
+
+	var below, recovered []BalanceEvent
+	for i, threshold := range m.Thresholds {
+		event := BalanceEvent{
+			AccountIdentifier: accountID,
+			Threshold:         threshold,
+			Balance:           balance,
+			CurrencyCode:      account.CurrencyCode,
+			Runway:            runway,
+			Time:              now,
+		}
+		switch {
+		case !state.below[i] && balance < threshold.Amount:
+			state.below[i] = true
+			below = append(below, event)
+		case state.below[i] && balance >= threshold.Amount+threshold.Hysteresis:
+			state.below[i] = false
+			recovered = append(recovered, event)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, event := range below {
+		if m.OnBelow != nil {
+			m.OnBelow(event)
+		}
+	}
+	for _, event := range recovered {
+		if m.OnRecovered != nil {
+			m.OnRecovered(event)
+		}
+	}
+}
+
+// Balance returns the last balance seen for an account and when it was seen.
+// ok is false if the account has not been polled successfully yet.
+func (m *BalanceMonitor) Balance(accountID string) (balance float64, updated time.Time, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, ok := m.accounts[accountID]
+	if !ok {
+		return 0, time.Time{}, false
+	}
+	return state.balance, state.updated, true
+}
+
+/*
+Runway estimates how long an account's balance will last, from the average
+rate it was spent over the SpendWindow. Increases in balance, such as
+deposits, are not counted as negative spend. ok is false until there is some
+spend to measure.
+*/
+func (m *BalanceMonitor) Runway(accountID string) (runway time.Duration, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state, found := m.accounts[accountID]
+	if !found {
+		return 0, false
+	}
+	return state.runway()
+}
+
+func (a *monitoredAccount) runway() (time.Duration, bool) {
+	if len(a.history) < 2 {
+		return 0, false
+	}
+
+	var spent float64
+	for i := 1; i < len(a.history); i++ {
+		if drop := a.history[i-1].balance - a.history[i].balance; drop > 0 {
+			spent += drop
+		}
+	}
+	span := a.history[len(a.history)-1].at.Sub(a.history[0].at)
+	if spent <= 0 || span <= 0 {
+		return 0, false
+	}
+	if a.balance <= 0 {
+		return 0, true
+	}
+
+	perSecond := spent / span.Seconds()
+	nanos := a.balance / perSecond * float64(time.Second)
+	if nanos >= math.MaxInt64 {
+		// Too far off for a Duration
+		return time.Duration(math.MaxInt64), true
+	}
+	return time.Duration(nanos), true
+}
+
+// trimHistory drops samples older than since, keeping the last one before it
+// so the window still has a starting point.
+func trimHistory(history []balanceSample, since time.Time) []balanceSample {
+	start := 0
+	for start < len(history)-1 && history[start+1].at.Before(since) {
+		start++
+	}
+	return history[start:]
+}
+
+func (m *BalanceMonitor) spendWindow() time.Duration {
+	if m.SpendWindow > 0 {
+		return m.SpendWindow
+	}
+	return DefaultSpendWindow
+}
+
+func (m *BalanceMonitor) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+	return time.Now()
+}
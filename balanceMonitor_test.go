@@ -0,0 +1,139 @@
+package tango
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+type fakeAccounts struct {
+	balances map[string]float64
+	err      error
+}
+
+func (f *fakeAccounts) GetAccountInfo(accountID string) (Account, error) {
+	if f.err != nil {
+		return Account{}, f.err
+	}
+	return Account{AccountIdentifier: accountID, CurrencyCode: "USD", Balance: f.balances[accountID]}, nil
+}
+
+func TestBalanceMonitor_Thresholds(t *testing.T) {
+	accounts := &fakeAccounts{balances: map[string]float64{"ops": 1500}}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var below, recovered []BalanceEvent
+	monitor := &BalanceMonitor{
+		Client:      accounts,
+		Accounts:    []string{"ops"},
+		Thresholds:  []BalanceThreshold{{Amount: 1000, Hysteresis: 200}, {Amount: 500}},
+		OnBelow:     func(e BalanceEvent) { below = append(below, e) },
+		OnRecovered: func(e BalanceEvent) { recovered = append(recovered, e) },
+		Now:         func() time.Time { return now },
+	}
+
+	// Each step sets the balance, polls, and checks the running totals
+	steps := []struct {
+		balance   float64
+		below     int
+		recovered int
+	}{
+		{1500, 0, 0},
+		{900, 1, 0},  // crosses 1000
+		{1050, 1, 0}, // above 1000 but inside the hysteresis band
+		{950, 1, 0},  // no second alert
+		{400, 2, 0},  // crosses 500
+		{1250, 2, 2}, // clears both
+		{990, 3, 2},  // re-armed 1000 fires again
+	}
+	for i, step := range steps {
+		now = now.Add(time.Hour)
+		accounts.balances["ops"] = step.balance
+		monitor.Poll()
+		if len(below) != step.below || len(recovered) != step.recovered {
+			t.Fatalf("step %d (balance %v): got %d below and %d recovered, want %d and %d",
+				i, step.balance, len(below), len(recovered), step.below, step.recovered)
+		}
+	}
+
+	if below[1].Threshold.Amount != 500 || below[1].Balance != 400 || below[1].CurrencyCode != "USD" {
+		t.Fatalf("unexpected event: %+v", below[1])
+	}
+
+	balance, updated, ok := monitor.Balance("ops")
+	if !ok || balance != 990 || !updated.Equal(now) {
+		t.Fatalf("unexpected last balance %v at %v", balance, updated)
+	}
+	if _, _, ok := monitor.Balance("other"); ok {
+		t.Fatal("expected no balance for an account that is not monitored")
+	}
+}
+
+func TestBalanceMonitor_Runway(t *testing.T) {
+	accounts := &fakeAccounts{balances: map[string]float64{"ops": 1000}}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	monitor := &BalanceMonitor{
+		Client:      accounts,
+		Accounts:    []string{"ops"},
+		SpendWindow: 36 * time.Hour,
+		Now:         func() time.Time { return now },
+	}
+
+	monitor.Poll()
+	if _, ok := monitor.Runway("ops"); ok {
+		t.Fatal("expected no runway from a single sample")
+	}
+
+	// The first day drops out of the window, leaving 100 spent over two days;
+	// the deposit does not offset it.
+	for _, balance := range []float64{900, 1800, 1700} {
+		now = now.Add(24 * time.Hour)
+		accounts.balances["ops"] = balance
+		monitor.Poll()
+	}
+
+	runway, ok := monitor.Runway("ops")
+	if !ok || runway != 34*24*time.Hour {
+		t.Fatalf("expected 34 days of runway, got %v (%v)", runway, ok)
+	}
+}
+
+func TestBalanceMonitor_RunwayOverflow(t *testing.T) {
+	accounts := &fakeAccounts{balances: map[string]float64{"ops": 100000001.5}}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	monitor := &BalanceMonitor{
+		Client:      accounts,
+		Accounts:    []string{"ops"},
+		SpendWindow: 30 * 24 * time.Hour,
+		Now:         func() time.Time { return now },
+	}
+
+	monitor.Poll()
+	now = now.Add(7 * 24 * time.Hour)
+	accounts.balances["ops"] = 100000000.5
+	monitor.Poll()
+
+	runway, ok := monitor.Runway("ops")
+	if !ok || runway != time.Duration(math.MaxInt64) {
+		t.Fatalf("expected the longest runway, got %v (%v)", runway, ok)
+	}
+}
+
+func TestBalanceMonitor_Errors(t *testing.T) {
+	accounts := &fakeAccounts{err: errors.New("unavailable")}
+	var failed []string
+	monitor := &BalanceMonitor{
+		Client:   accounts,
+		Accounts: []string{"a", "b"},
+		OnError:  func(accountID string, err error) { failed = append(failed, accountID) },
+	}
+
+	monitor.Poll()
+	if len(failed) != 2 {
+		t.Fatalf("expected both accounts to report errors, got %v", failed)
+	}
+	if _, _, ok := monitor.Balance("a"); ok {
+		t.Fatal("expected no balance after a failed poll")
+	}
+}
@@ -0,0 +1,175 @@
+package barcode
+
+import (
+	"bytes"
+	"encoding/xml"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/oned"
+	"github.com/makiuchi-d/gozxing/qrcode"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+func decodePNG(t *testing.T, data []byte) image.Image {
+	t.Helper()
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("decode png: %v", err)
+	}
+	return img
+}
+
+// rasterizeSVG draws the rectangles of an SVG written by WriteSVG.
+func rasterizeSVG(t *testing.T, data []byte) image.Image {
+	t.Helper()
+
+	var doc struct {
+		Width  int `xml:"width,attr"`
+		Height int `xml:"height,attr"`
+		Rects  []struct {
+			X      int    `xml:"x,attr"`
+			Y      int    `xml:"y,attr"`
+			Width  int    `xml:"width,attr"`
+			Height int    `xml:"height,attr"`
+			Fill   string `xml:"fill,attr"`
+		} `xml:"rect"`
+	}
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("parse svg: %v", err)
+	}
+
+	img := image.NewGray(image.Rect(0, 0, doc.Width, doc.Height))
+	for _, rect := range doc.Rects {
+		shade := color.Gray{Y: 0xff}
+		if rect.Fill == "#000000" {
+			shade = color.Gray{Y: 0}
+		}
+		for y := rect.Y; y < rect.Y+rect.Height; y++ {
+			for x := rect.X; x < rect.X+rect.Width; x++ {
+				img.SetGray(x, y, shade)
+			}
+		}
+	}
+	return img
+}
+
+func scan(t *testing.T, img image.Image, reader gozxing.Reader) string {
+	t.Helper()
+
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		t.Fatalf("bitmap: %v", err)
+	}
+	result, err := reader.Decode(bitmap, nil)
+	if err != nil {
+		t.Fatalf("scan failed: %v", err)
+	}
+	return result.GetText()
+}
+
+func TestRender_DecodesBack(t *testing.T) {
+	tests := []struct {
+		format Format
+		value  string
+		reader func() gozxing.Reader
+	}{
+		{Code128, "6006491234567890", oned.NewCode128Reader},
+		{Code128, "ABC-123/xyz", oned.NewCode128Reader},
+		{QR, "https://rewards.example.com/r/abc123", qrcode.NewQRCodeReader},
+		{QR, "6006491234567890", qrcode.NewQRCodeReader},
+	}
+
+	for _, tt := range tests {
+		cred := tango.CredentialList{Label: "Barcode", Value: tt.value, Type: "barcode", CredentialType: "barcode"}
+		opts := Options{Format: tt.format, ModuleSize: 3}
+
+		var pngBuf, svgBuf bytes.Buffer
+		if err := RenderPNG(&pngBuf, cred, opts); err != nil {
+			t.Fatalf("render png: %v", err)
+		}
+		if err := RenderSVG(&svgBuf, cred, opts); err != nil {
+			t.Fatalf("render svg: %v", err)
+		}
+
+		if got := scan(t, decodePNG(t, pngBuf.Bytes()), tt.reader()); got != tt.value {
+			t.Fatalf("%s png decoded to %q, want %q", tt.format, got, tt.value)
+		}
+		if got := scan(t, rasterizeSVG(t, svgBuf.Bytes()), tt.reader()); got != tt.value {
+			t.Fatalf("%s svg decoded to %q, want %q", tt.format, got, tt.value)
+		}
+	}
+}
+
+// There is no pure-Go PDF417 reader, so the PDF417 test reads the module grid
+// back out of the PNG and SVG images and compares it with a fresh encoding of
+// the value. This catches rendering bugs, not encoding ones.
+func TestRender_PDF417ModulesMatch(t *testing.T) {
+	const value = "6006491234567890 PIN 4321"
+	opts := Options{Format: PDF417, ModuleSize: 2, QuietZone: 3}
+	cred := tango.CredentialList{Label: "Barcode", Value: value, Type: "barcode", CredentialType: "barcode"}
+
+	var pngBuf, svgBuf bytes.Buffer
+	if err := RenderPNG(&pngBuf, cred, opts); err != nil {
+		t.Fatalf("render png: %v", err)
+	}
+	if err := RenderSVG(&svgBuf, cred, opts); err != nil {
+		t.Fatalf("render svg: %v", err)
+	}
+
+	expected, err := Encode(value, Options{Format: PDF417})
+	if err != nil {
+		t.Fatalf("re-encode: %v", err)
+	}
+	margin := opts.QuietZone * opts.ModuleSize
+	width := len(expected.Modules[0])*opts.ModuleSize + 2*margin
+	height := len(expected.Modules)*opts.ModuleSize + 2*margin
+
+	images := map[string]image.Image{
+		"png": decodePNG(t, pngBuf.Bytes()),
+		"svg": rasterizeSVG(t, svgBuf.Bytes()),
+	}
+	for name, img := range images {
+		if img.Bounds().Dx() != width || img.Bounds().Dy() != height {
+			t.Fatalf("%s image is %v, want %dx%d", name, img.Bounds(), width, height)
+		}
+
+		for x := 0; x < width; x++ {
+			if isDark(img.At(x, margin-1)) || isDark(img.At(x, height-margin)) {
+				t.Fatalf("%s quiet zone is not blank at column %d", name, x)
+			}
+		}
+
+		for y, row := range expected.Modules {
+			for x, dark := range row {
+				px := margin + x*opts.ModuleSize + opts.ModuleSize/2
+				py := margin + y*opts.ModuleSize + opts.ModuleSize/2
+				if isDark(img.At(px, py)) != dark {
+					t.Fatalf("%s module (%d,%d) does not match the encoded value", name, x, y)
+				}
+			}
+		}
+	}
+}
+
+func TestFromCredential(t *testing.T) {
+	_, err := FromCredential(tango.CredentialList{Label: "PIN", Value: "1234", Type: "text", CredentialType: "pin"}, Options{})
+	if err == nil {
+		t.Fatalf("expected error for non-barcode credential")
+	}
+
+	symbol, err := FromCredential(tango.CredentialList{Label: "QR Code", Value: "abc", Type: "barcode"}, Options{})
+	if err != nil || symbol.Format != QR {
+		t.Fatalf("expected QR format from label, got %v, %v", symbol, err)
+	}
+
+	symbol, err = FromCredential(tango.CredentialList{Label: "Barcode", Value: "abc", Type: "barcode"}, Options{})
+	if err != nil || symbol.Format != Code128 || len(symbol.Modules) != 1 {
+		t.Fatalf("expected Code128 default, got %v, %v", symbol, err)
+	}
+}
@@ -0,0 +1,61 @@
+package barcode
+
+import (
+	"bufio"
+	"fmt"
+	"image/png"
+	"io"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+// WritePNG writes the barcode as a PNG image.
+func (s *Symbol) WritePNG(w io.Writer) error {
+	return png.Encode(w, s.Image())
+}
+
+// WriteSVG writes the barcode as an SVG image. Adjacent dark modules in a row
+// are merged into a single rectangle to keep the document small.
+func (s *Symbol) WriteSVG(w io.Writer) error {
+	width, height := s.Size()
+	margin := s.quietZone * s.moduleSize
+	rowHeight := s.rowHeight()
+
+	buf := bufio.NewWriter(w)
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+"\n", width, height, width, height)
+	fmt.Fprintf(buf, `<rect x="0" y="0" width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	for y, row := range s.Modules {
+		for x := 0; x < len(row); {
+			if !row[x] {
+				x++
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="#000000"/>`+"\n",
+				margin+start*s.moduleSize, margin+y*rowHeight, (x-start)*s.moduleSize, rowHeight)
+		}
+	}
+	fmt.Fprint(buf, "</svg>\n")
+	return buf.Flush()
+}
+
+// RenderPNG encodes a barcode-type credential and writes it as a PNG image.
+func RenderPNG(w io.Writer, cred tango.CredentialList, opts Options) error {
+	symbol, err := FromCredential(cred, opts)
+	if err != nil {
+		return err
+	}
+	return symbol.WritePNG(w)
+}
+
+// RenderSVG encodes a barcode-type credential and writes it as an SVG image.
+func RenderSVG(w io.Writer, cred tango.CredentialList, opts Options) error {
+	symbol, err := FromCredential(cred, opts)
+	if err != nil {
+		return err
+	}
+	return symbol.WriteSVG(w)
+}
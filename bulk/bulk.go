@@ -0,0 +1,130 @@
+// Package bulk reads batches of Tango orders from CSV or NDJSON (JSON Lines)
+// and writes the outcome of submitting them back out in the same formats.
+package bulk
+
+import (
+	"fmt"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+// Row is a single order read from a batch file.
+type Row struct {
+	// Line is the 1-based line number of the record in the source file.
+	Line  int
+	Order tango.CreateOrderData
+	// Vars holds the row's template variables, read from "vars.<name>"
+	// columns or a "vars" object.
+	Vars map[string]string
+}
+
+// RowError describes a problem with a single row of a batch file.
+type RowError struct {
+	Line  int
+	Field string
+	Err   error
+}
+
+func (e *RowError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: %s: %v", e.Line, e.Field, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Batch is the result of reading a batch file. Rows holds every row that
+// parsed and validated cleanly; rows with problems are reported in Errors.
+type Batch struct {
+	Rows   []Row
+	Errors []*RowError
+}
+
+// Valid reports whether every row in the file was read without errors.
+func (b Batch) Valid() bool {
+	return len(b.Errors) == 0
+}
+
+// Orders returns the order data of every valid row.
+func (b Batch) Orders() []tango.CreateOrderData {
+	orders := make([]tango.CreateOrderData, 0, len(b.Rows))
+	for _, row := range b.Rows {
+		orders = append(orders, row.Order)
+	}
+	return orders
+}
+
+// Orderer is implemented by *tango.TangoClient.
+type Orderer interface {
+	Order(data tango.CreateOrderData) (tango.CreateOrderResponse, error)
+}
+
+// Result is the outcome of submitting a single row.
+type Result struct {
+	Line     int
+	Order    tango.CreateOrderData
+	Response tango.CreateOrderResponse
+	Err      error
+}
+
+// Submit places an order for each row, in order, and returns one Result per row.
+// A failed order does not stop the remaining rows from being submitted.
+func Submit(client Orderer, rows []Row) []Result {
+	results := make([]Result, 0, len(rows))
+	for _, row := range rows {
+		resp, err := client.Order(row.Order)
+		results = append(results, Result{
+			Line:     row.Line,
+			Order:    row.Order,
+			Response: resp,
+			Err:      err,
+		})
+	}
+	return results
+}
+
+// WriteOptions controls how results are written.
+type WriteOptions struct {
+	// IncludeCredentials adds the reward credentials to the output. They are
+	// left out by default so result files can be shared safely.
+	IncludeCredentials bool
+}
+
+type resultRecord struct {
+	Line               int                    `json:"line"`
+	ExternalRefID      string                 `json:"externalRefID,omitempty"`
+	CustomerIdentifier string                 `json:"customerIdentifier,omitempty"`
+	Utid               string                 `json:"utid,omitempty"`
+	ReferenceOrderID   string                 `json:"referenceOrderID,omitempty"`
+	Status             string                 `json:"status,omitempty"`
+	AmountCharged      *tango.Amount          `json:"amountCharged,omitempty"`
+	Error              string                 `json:"error,omitempty"`
+	Credentials        []tango.CredentialList `json:"credentials,omitempty"`
+}
+
+func newResultRecord(result Result, opts WriteOptions) resultRecord {
+	record := resultRecord{
+		Line:               result.Line,
+		ExternalRefID:      result.Order.ExternalRefID,
+		CustomerIdentifier: result.Order.CustomerIdentifier,
+		Utid:               result.Order.Utid,
+	}
+
+	if result.Err != nil {
+		record.Status = "ERROR"
+		record.Error = result.Err.Error()
+		return record
+	}
+
+	record.ReferenceOrderID = result.Response.ReferenceOrderID
+	record.Status = result.Response.Status
+	amountCharged := result.Response.AmountCharged
+	record.AmountCharged = &amountCharged
+	if opts.IncludeCredentials {
+		record.Credentials = result.Response.Reward.CredentialList
+	}
+	return record
+}
@@ -0,0 +1,196 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"testing"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+func TestReadCSV_CustomMapping(t *testing.T) {
+	input := "Ref,Customer,Card,Value,Method,First,Email,Street,City,From\n" +
+		"r-1,cust,U123,25.50,email,Ada,ada@example.com,1 Main St,Springfield,Ops Team\n" +
+		"r-2,cust,U123,10,embedded,Bob,,2 Side St,Shelbyville,\n"
+
+	mapping := Mapping{
+		"Ref":      "externalRefID",
+		"Customer": "customerIdentifier",
+		"Card":     "utid",
+		"Value":    "amount",
+		"Method":   "deliveryMethod",
+		"First":    "recipient.firstName",
+		"Email":    "recipient.email",
+		"Street":   "recipient.address.streetLine1",
+		"City":     "recipient.address.city",
+		"From":     "sender.firstName",
+	}
+
+	batch, err := ReadCSV(strings.NewReader(input), mapping)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !batch.Valid() {
+		t.Fatalf("expected valid batch, got errors: %v", batch.Errors)
+	}
+	if len(batch.Rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(batch.Rows))
+	}
+
+	first := batch.Rows[0]
+	if first.Line != 2 {
+		t.Fatalf("expected first row on line 2, got %d", first.Line)
+	}
+	if first.Order.Amount != 25.50 || first.Order.DeliveryMethod != "EMAIL" {
+		t.Fatalf("unexpected order: %+v", first.Order)
+	}
+	if first.Order.Recipient.Address.StreetLine1 != "1 Main St" || first.Order.Sender.FirstName != "Ops Team" {
+		t.Fatalf("nested fields not mapped: %+v", first.Order)
+	}
+}
+
+func TestReadCSV_RowErrors(t *testing.T) {
+	input := "customerIdentifier,utid,amount,deliveryMethod,recipient.firstName,recipient.email\n" +
+		"cust,U1,5,EMAIL,Ada,ok@example.com\n" +
+		"cust,,abc,EMAIL,Ada,\n" +
+		",U1,0,FAX,,not-an-email\n"
+
+	batch, err := ReadCSV(strings.NewReader(input), nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(batch.Rows) != 1 {
+		t.Fatalf("expected 1 valid row, got %d", len(batch.Rows))
+	}
+
+	lines := map[int][]string{}
+	for _, rowErr := range batch.Errors {
+		lines[rowErr.Line] = append(lines[rowErr.Line], rowErr.Field)
+	}
+	if got := lines[3]; len(got) != 1 || got[0] != "amount" {
+		t.Fatalf("expected amount parse error on line 3, got %v", got)
+	}
+	if got := lines[4]; len(got) != 4 {
+		t.Fatalf("expected 4 validation errors on line 4, got %v", got)
+	}
+}
+
+func TestReadCSV_MissingMappedColumn(t *testing.T) {
+	_, err := ReadCSV(strings.NewReader("a,b\n1,2\n"), Mapping{"Utid": "utid"})
+	if err == nil {
+		t.Fatalf("expected error for missing mapped column")
+	}
+
+	_, err = ReadCSV(strings.NewReader("a\n1\n"), Mapping{"a": "recipient.phone"})
+	if err == nil {
+		t.Fatalf("expected error for unknown field")
+	}
+}
+
+func TestReadNDJSON(t *testing.T) {
+	input := `{"customerIdentifier":"cust","utid":"U1","amount":5,"recipient":{"email":"a@example.com","address":{"city":"X"}}}
+
+{"customerIdentifier":"cust","utid":"U1","amount":5,"bogus":true}
+{"customerIdentifier":"cust","utid":"U1"}
+`
+	batch, err := ReadNDJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(batch.Rows) != 1 || batch.Rows[0].Order.Recipient.Address.City != "X" {
+		t.Fatalf("unexpected rows: %+v", batch.Rows)
+	}
+	if len(batch.Errors) != 2 || batch.Errors[0].Line != 3 || batch.Errors[1].Field != "amount" {
+		t.Fatalf("unexpected errors: %v", batch.Errors)
+	}
+}
+
+type fakeOrderer struct{}
+
+func (fakeOrderer) Order(data tango.CreateOrderData) (tango.CreateOrderResponse, error) {
+	if data.Utid == "bad" {
+		return tango.CreateOrderResponse{}, errors.New("declined")
+	}
+	return tango.CreateOrderResponse{
+		ReferenceOrderID: "RA-" + data.ExternalRefID,
+		Status:           "COMPLETE",
+		AmountCharged:    tango.Amount{Value: data.Amount, CurrencyCode: "USD", Total: data.Amount},
+		Reward: tango.Reward{CredentialList: []tango.CredentialList{
+			{Label: "PIN", Value: "1234", CredentialType: "pin"},
+		}},
+	}, nil
+}
+
+func TestWriteResults(t *testing.T) {
+	rows := []Row{
+		{Line: 2, Order: tango.CreateOrderData{ExternalRefID: "a", Utid: "U1", Amount: 5}},
+		{Line: 3, Order: tango.CreateOrderData{ExternalRefID: "b", Utid: "bad", Amount: 5}},
+	}
+	results := Submit(fakeOrderer{}, rows)
+
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, results, WriteOptions{}); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read back csv: %v", err)
+	}
+	if len(records) != 3 || len(records[0]) != len(csvResultHeader) {
+		t.Fatalf("unexpected csv output: %v", records)
+	}
+	if records[1][4] != "RA-a" || records[1][6] != "5" || records[2][5] != "ERROR" || records[2][10] != "declined" {
+		t.Fatalf("unexpected csv rows: %v", records[1:])
+	}
+	if strings.Contains(buf.String(), "1234") {
+		t.Fatalf("credentials written without being requested")
+	}
+
+	buf.Reset()
+	if err := WriteNDJSON(&buf, results, WriteOptions{}); err != nil {
+		t.Fatalf("write ndjson: %v", err)
+	}
+	if strings.Contains(buf.String(), "1234") {
+		t.Fatalf("credentials written without being requested")
+	}
+
+	buf.Reset()
+	if err := WriteNDJSON(&buf, results, WriteOptions{IncludeCredentials: true}); err != nil {
+		t.Fatalf("write ndjson: %v", err)
+	}
+	if !strings.Contains(buf.String(), `"value":"1234"`) {
+		t.Fatalf("expected credentials in output, got %s", buf.String())
+	}
+}
+
+func TestApplyTemplate(t *testing.T) {
+	input := `utid,customerIdentifier,amount,recipient.firstName,vars.team
+U1,cust,5,Ann,Platform
+U1,cust,5,Bo,
+`
+	batch, err := ReadCSV(strings.NewReader(input), nil)
+	if err != nil || !batch.Valid() {
+		t.Fatalf("read: %v %v", err, batch.Errors)
+	}
+
+	ndjson, err := ReadNDJSON(strings.NewReader(`{"customerIdentifier":"cust","utid":"U1","amount":5,"recipient":{"firstName":"Cy"},"vars":{"team":"Data"}}`))
+	if err != nil || !ndjson.Valid() {
+		t.Fatalf("read ndjson: %v %v", err, ndjson.Errors)
+	}
+	batch.Rows = append(batch.Rows, ndjson.Rows...)
+
+	tmpl, err := tango.NewMessageTemplate("", "{{if .Vars.team}}Thanks from {{.Vars.team}}, {{.Recipient.FirstName}}{{else}}{{.Vars.missing}}{{end}}")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	rendered := ApplyTemplate(batch, tmpl)
+	if len(rendered.Rows) != 2 || rendered.Rows[0].Order.Message != "Thanks from Platform, Ann" || rendered.Rows[1].Order.Message != "Thanks from Data, Cy" {
+		t.Fatalf("unexpected rows: %+v", rendered.Rows)
+	}
+	if len(rendered.Errors) != 1 || rendered.Errors[0].Line != 3 {
+		t.Fatalf("expected the row with no team to fail, got %v", rendered.Errors)
+	}
+}
@@ -0,0 +1,187 @@
+package bulk
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+/*
+ReadCSV reads orders from CSV data with a header row.
+
+The mapping ties column headers to CreateOrderData fields. A nil mapping uses
+DefaultMapping, where the headers are the field paths themselves; columns not
+named in the mapping are ignored. Every column named in the mapping must be
+present in the header.
+
+Problems with individual rows are collected in Batch.Errors so the whole file
+can be reviewed at once. The returned error is reserved for problems with the
+file itself, such as a missing header.
+*/
+func ReadCSV(r io.Reader, mapping Mapping) (Batch, error) {
+	requireColumns := mapping != nil
+	if mapping == nil {
+		mapping = DefaultMapping()
+	}
+	for column, field := range mapping {
+		if !isField(field) {
+			return Batch{}, fmt.Errorf("column %q is mapped to unknown field %q", column, field)
+		}
+	}
+
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return Batch{}, fmt.Errorf("csv header is missing")
+	}
+	if err != nil {
+		return Batch{}, err
+	}
+
+	// columns[i] is the field path for column i, or "" if the column is unmapped
+	columns := make([]string, len(header))
+	seen := make(map[string]bool, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		columns[i] = mapping[name]
+		if columns[i] == "" && !requireColumns && isField(name) {
+			columns[i] = name
+		}
+		seen[name] = true
+	}
+	for column := range mapping {
+		if requireColumns && !seen[column] {
+			return Batch{}, fmt.Errorf("csv header is missing mapped column %q", column)
+		}
+	}
+
+	var batch Batch
+	reader.FieldsPerRecord = len(header)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			batch.Errors = append(batch.Errors, &RowError{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return batch, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		var order tango.CreateOrderData
+		var vars map[string]string
+		var rowErrs []*RowError
+		for i, value := range record {
+			field := columns[i]
+			if field == "" {
+				continue
+			}
+			if strings.HasPrefix(field, varsPrefix) {
+				if vars == nil {
+					vars = make(map[string]string)
+				}
+				vars[strings.TrimPrefix(field, varsPrefix)] = strings.TrimSpace(value)
+				continue
+			}
+			if err := fieldSetters[field](&order, strings.TrimSpace(value)); err != nil {
+				rowErrs = append(rowErrs, &RowError{Line: line, Field: field, Err: err})
+			}
+		}
+		if len(rowErrs) == 0 {
+			rowErrs = validateOrder(line, order)
+		}
+
+		if len(rowErrs) > 0 {
+			batch.Errors = append(batch.Errors, rowErrs...)
+			continue
+		}
+		batch.Rows = append(batch.Rows, Row{Line: line, Order: order, Vars: vars})
+	}
+
+	return batch, nil
+}
+
+var csvResultHeader = []string{
+	"line",
+	"externalRefID",
+	"customerIdentifier",
+	"utid",
+	"referenceOrderID",
+	"status",
+	"amountCharged.value",
+	"amountCharged.currencyCode",
+	"amountCharged.fee",
+	"amountCharged.total",
+	"error",
+}
+
+/*
+WriteCSV writes one CSV row per result with the reference order ID, status and
+amount charged, or the error for orders that failed. Credentials are written as
+a JSON-encoded column only when opts.IncludeCredentials is set.
+*/
+func WriteCSV(w io.Writer, results []Result, opts WriteOptions) error {
+	writer := csv.NewWriter(w)
+
+	header := csvResultHeader
+	if opts.IncludeCredentials {
+		header = append(append([]string{}, csvResultHeader...), "credentials")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		record := newResultRecord(result, opts)
+		row := []string{
+			strconv.Itoa(record.Line),
+			record.ExternalRefID,
+			record.CustomerIdentifier,
+			record.Utid,
+			record.ReferenceOrderID,
+			record.Status,
+			"", "", "", "",
+			record.Error,
+		}
+		if record.AmountCharged != nil {
+			row[6] = formatFloat(record.AmountCharged.Value)
+			row[7] = record.AmountCharged.CurrencyCode
+			row[8] = formatFloat(record.AmountCharged.Fee)
+			row[9] = formatFloat(record.AmountCharged.Total)
+		}
+		if opts.IncludeCredentials {
+			credentials := ""
+			if len(record.Credentials) > 0 {
+				encoded, err := json.Marshal(record.Credentials)
+				if err != nil {
+					return err
+				}
+				credentials = string(encoded)
+			}
+			row = append(row, credentials)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,102 @@
+package bulk
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+// Mapping maps CSV column headers to CreateOrderData field paths, e.g.
+//
+//	"Email":   "recipient.email",
+//	"Street":  "recipient.address.streetLine1",
+//	"Value":   "amount",
+//
+// Field paths use the JSON names of the fields. See Fields for the full list.
+// A path of the form "vars.<name>" stores the column in Row.Vars under name,
+// for use with ApplyTemplate.
+type Mapping map[string]string
+
+// varsPrefix marks a field path that sets a template variable.
+const varsPrefix = "vars."
+
+// isField reports whether path is a field path a Mapping can use.
+func isField(path string) bool {
+	if strings.HasPrefix(path, varsPrefix) {
+		return len(path) > len(varsPrefix)
+	}
+	_, ok := fieldSetters[path]
+	return ok
+}
+
+type fieldSetter func(order *tango.CreateOrderData, value string) error
+
+func stringField(get func(order *tango.CreateOrderData) *string) fieldSetter {
+	return func(order *tango.CreateOrderData, value string) error {
+		*get(order) = value
+		return nil
+	}
+}
+
+var fieldSetters = map[string]fieldSetter{
+	"externalRefID":      stringField(func(o *tango.CreateOrderData) *string { return &o.ExternalRefID }),
+	"customerIdentifier": stringField(func(o *tango.CreateOrderData) *string { return &o.CustomerIdentifier }),
+	"utid":               stringField(func(o *tango.CreateOrderData) *string { return &o.Utid }),
+	"amount": func(o *tango.CreateOrderData, value string) error {
+		if value == "" {
+			o.Amount = 0
+			return nil
+		}
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid amount %q", value)
+		}
+		o.Amount = amount
+		return nil
+	},
+	"emailSubject": stringField(func(o *tango.CreateOrderData) *string { return &o.EmailSubject }),
+	"message":      stringField(func(o *tango.CreateOrderData) *string { return &o.Message }),
+	"etid":         stringField(func(o *tango.CreateOrderData) *string { return &o.Etid }),
+	"campaign":     stringField(func(o *tango.CreateOrderData) *string { return &o.Campaign }),
+	"notes":        stringField(func(o *tango.CreateOrderData) *string { return &o.Notes }),
+	"deliveryMethod": func(o *tango.CreateOrderData, value string) error {
+		o.DeliveryMethod = strings.ToUpper(value)
+		return nil
+	},
+	"sender.firstName":                  stringField(func(o *tango.CreateOrderData) *string { return &o.Sender.FirstName }),
+	"sender.lastName":                   stringField(func(o *tango.CreateOrderData) *string { return &o.Sender.LastName }),
+	"sender.email":                      stringField(func(o *tango.CreateOrderData) *string { return &o.Sender.Email }),
+	"recipient.firstName":               stringField(func(o *tango.CreateOrderData) *string { return &o.Recipient.FirstName }),
+	"recipient.lastName":                stringField(func(o *tango.CreateOrderData) *string { return &o.Recipient.LastName }),
+	"recipient.email":                   stringField(func(o *tango.CreateOrderData) *string { return &o.Recipient.Email }),
+	"recipient.phoneNumber":             stringField(func(o *tango.CreateOrderData) *string { return &o.Recipient.PhoneNumber }),
+	"recipient.address.streetLine1":     stringField(func(o *tango.CreateOrderData) *string { return &o.Recipient.Address.StreetLine1 }),
+	"recipient.address.streetLine2":     stringField(func(o *tango.CreateOrderData) *string { return &o.Recipient.Address.StreetLine2 }),
+	"recipient.address.city":            stringField(func(o *tango.CreateOrderData) *string { return &o.Recipient.Address.City }),
+	"recipient.address.stateOrProvince": stringField(func(o *tango.CreateOrderData) *string { return &o.Recipient.Address.StateOrProvince }),
+	"recipient.address.postalCode":      stringField(func(o *tango.CreateOrderData) *string { return &o.Recipient.Address.PostalCode }),
+	"recipient.address.country":         stringField(func(o *tango.CreateOrderData) *string { return &o.Recipient.Address.Country }),
+}
+
+// Fields returns every field path that can be used in a Mapping, sorted.
+func Fields() []string {
+	fields := make([]string, 0, len(fieldSetters))
+	for field := range fieldSetters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// DefaultMapping maps every supported field path to a column of the same name.
+// ReadCSV also reads "vars.<name>" columns when it uses the default mapping.
+func DefaultMapping() Mapping {
+	mapping := make(Mapping, len(fieldSetters))
+	for field := range fieldSetters {
+		mapping[field] = field
+	}
+	return mapping
+}
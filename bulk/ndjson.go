@@ -0,0 +1,68 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+const maxNDJSONLineSize = 1 << 20
+
+/*
+ReadNDJSON reads orders from JSON Lines data, one CreateOrderData object per
+line. Blank lines are skipped and unknown fields are reported as row errors.
+An optional "vars" object of strings sets the row's template variables.
+*/
+func ReadNDJSON(r io.Reader) (Batch, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxNDJSONLineSize)
+
+	var batch Batch
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+
+		var record struct {
+			tango.CreateOrderData
+			Vars map[string]string `json:"vars"`
+		}
+		if err := decoder.Decode(&record); err != nil {
+			batch.Errors = append(batch.Errors, &RowError{Line: line, Err: err})
+			continue
+		}
+
+		order := record.CreateOrderData
+		if rowErrs := validateOrder(line, order); len(rowErrs) > 0 {
+			batch.Errors = append(batch.Errors, rowErrs...)
+			continue
+		}
+		batch.Rows = append(batch.Rows, Row{Line: line, Order: order, Vars: record.Vars})
+	}
+	if err := scanner.Err(); err != nil {
+		return batch, err
+	}
+
+	return batch, nil
+}
+
+// WriteNDJSON writes one JSON object per result. Credentials are included only
+// when opts.IncludeCredentials is set.
+func WriteNDJSON(w io.Writer, results []Result, opts WriteOptions) error {
+	encoder := json.NewEncoder(w)
+	for _, result := range results {
+		if err := encoder.Encode(newResultRecord(result, opts)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
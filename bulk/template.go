@@ -0,0 +1,28 @@
+package bulk
+
+import (
+	"sort"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+// ApplyTemplate renders the email subject and message of every row in the
+// batch from tmpl, using each row's recipient, sender and Vars. Rows that fail
+// to render, or render too long, move to Errors.
+func ApplyTemplate(batch Batch, tmpl *tango.MessageTemplate) Batch {
+	result := Batch{Errors: append([]*RowError{}, batch.Errors...)}
+	for _, row := range batch.Rows {
+		order, err := tmpl.Apply(row.Order, row.Vars)
+		if err != nil {
+			result.Errors = append(result.Errors, rowErrors(row.Line, err)...)
+			continue
+		}
+		row.Order = order
+		result.Rows = append(result.Rows, row)
+	}
+
+	sort.SliceStable(result.Errors, func(i, j int) bool {
+		return result.Errors[i].Line < result.Errors[j].Line
+	})
+	return result
+}
@@ -0,0 +1,30 @@
+package bulk
+
+import (
+	"errors"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+// validateOrder reports each field CreateOrderData.Validate rejects as a row error.
+func validateOrder(line int, order tango.CreateOrderData) []*RowError {
+	return rowErrors(line, order.Validate())
+}
+
+// rowErrors splits ValidationErrors into one row error per field.
+func rowErrors(line int, err error) []*RowError {
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs tango.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return []*RowError{{Line: line, Err: err}}
+	}
+
+	rowErrs := make([]*RowError, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		rowErrs = append(rowErrs, &RowError{Line: line, Field: fieldErr.Field, Err: errors.New(fieldErr.Message)})
+	}
+	return rowErrs
+}
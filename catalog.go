@@ -2,8 +2,6 @@ package tango
 
 import (
 	"encoding/json"
-
-	"github.com/go-resty/resty/v2"
 )
 
 type Catalog struct {
@@ -78,13 +76,12 @@ of content and their status. This catalog endpoint should not be used per indivi
 or with frequent polling system.
 */
 func (c *TangoClient) GetCatalogItems() (Catalog, error) {
-	url := ApiURL + "/catalogs?verbose=true"
+	url := c.apiURL() + "/catalogs?verbose=true"
 
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		Get(url)
 	if err != nil {
 		return Catalog{}, err
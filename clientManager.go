@@ -0,0 +1,184 @@
+package tango
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// TenantConfig holds the credentials and settings for one tenant of a
+// ClientManager. It can be read from JSON. String, GoString and LogValue mask
+// the client secret and service account password.
+type TenantConfig struct {
+	ClientID               string `json:"clientID"`
+	ClientSecret           string `json:"clientSecret"`
+	ServiceAccountUsername string `json:"serviceAccountUsername,omitempty"`
+	ServiceAccountPassword string `json:"serviceAccountPassword,omitempty"`
+	// Environment is "production" or "sandbox".
+	Environment       string `json:"environment"`
+	AccountIdentifier string `json:"accountIdentifier"`
+	SendEmail         bool   `json:"sendEmail"`
+	// BaseURL, when set, overrides the API URL for the environment.
+	BaseURL string `json:"baseURL,omitempty"`
+}
+
+func (t TenantConfig) validate() error {
+	if err := validateTokenInputs(t.ClientID, t.ClientSecret, t.Environment); err != nil {
+		return err
+	}
+	if t.AccountIdentifier == "" {
+		return fmt.Errorf("accountIdentifier is required")
+	}
+	return nil
+}
+
+func (t TenantConfig) String() string {
+	return fmt.Sprintf("TenantConfig{ClientID: %s, ClientSecret: %s, ServiceAccountUsername: %s, ServiceAccountPassword: %s, Environment: %s, AccountIdentifier: %s, SendEmail: %t, BaseURL: %s}",
+		t.ClientID, maskCredential(t.ClientSecret, 0), t.ServiceAccountUsername, maskCredential(t.ServiceAccountPassword, 0),
+		t.Environment, t.AccountIdentifier, t.SendEmail, t.BaseURL)
+}
+
+func (t TenantConfig) GoString() string {
+	return t.String()
+}
+
+func (t TenantConfig) LogValue() slog.Value {
+	return slog.StringValue(t.String())
+}
+
+/*
+ClientManager holds the configuration of several tenants, each with its own
+OAuth client, environment and account, and hands out a TangoClient per
+tenant. Clients are built on first use and shared by every caller for the
+same tenant. Each client gets its token from a RefreshingTokenSource, so it
+stays usable after the token it started with expires.
+
+A ClientManager is safe for concurrent use.
+*/
+type ClientManager struct {
+	// TransferStore, when set, returns the TransferStore for a tenant's
+	// client. Return the same store for a tenant every time, so a client built
+	// after Reload keeps the TransferID records of the one it replaces. Set it
+	// before the first call to Client.
+	TransferStore func(tenant string) TransferStore
+
+	mu      sync.Mutex
+	tenants map[string]TenantConfig
+	clients map[string]*TangoClient
+}
+
+// NewClientManager returns a ClientManager for the given tenants, keyed by
+// name. Every config is validated, but no tokens are fetched until a tenant's
+// client is first used.
+func NewClientManager(tenants map[string]TenantConfig) (*ClientManager, error) {
+	m := &ClientManager{clients: make(map[string]*TangoClient)}
+	if err := m.Reload(tenants); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Client returns the shared client for a tenant, building it the first time,
+// or ErrUnknownTenant.
+func (m *ClientManager) Client(tenant string) (*TangoClient, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if client, ok := m.clients[tenant]; ok {
+		return client, nil
+	}
+	config, ok := m.tenants[tenant]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownTenant, tenant)
+	}
+
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = environmentURL(config.Environment)
+	}
+	client := &TangoClient{
+		Environment:       config.Environment,
+		SendEmail:         config.SendEmail,
+		AccountIdentifier: config.AccountIdentifier,
+		BaseURL:           baseURL,
+		TokenSource: &RefreshingTokenSource{
+			ClientID:               config.ClientID,
+			ClientSecret:           config.ClientSecret,
+			ServiceAccountUsername: config.ServiceAccountUsername,
+			ServiceAccountPassword: config.ServiceAccountPassword,
+			Environment:            config.Environment,
+		},
+	}
+	if m.TransferStore != nil {
+		client.TransferStore = m.TransferStore(tenant)
+	}
+	m.clients[tenant] = client
+	return client, nil
+}
+
+/*
+Reload replaces the tenant configuration. Tenants whose config is unchanged
+keep their client and token. A changed or removed tenant's client is dropped
+from the manager, but it is not closed: callers still holding it, including
+requests in flight, carry on with the old settings, and the next call to
+Client gets a client built from the new config.
+
+State a client keeps in memory is not carried over to its replacement: the
+new client starts without the ResendCooldown history or the cache of known
+email templates, and without the TransferID records unless TransferStore is
+set.
+
+If any config is invalid, nothing is changed.
+*/
+func (m *ClientManager) Reload(tenants map[string]TenantConfig) error {
+	var errs []error
+	for _, name := range sortedTenantNames(tenants) {
+		if strings.TrimSpace(name) == "" {
+			errs = append(errs, fmt.Errorf("tenant name is required"))
+			continue
+		}
+		if err := tenants[name].validate(); err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", name, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next := make(map[string]TenantConfig, len(tenants))
+	for name, config := range tenants {
+		next[name] = config
+	}
+	for name := range m.clients {
+		if config, ok := next[name]; !ok || config != m.tenants[name] {
+			delete(m.clients, name)
+		}
+	}
+	m.tenants = next
+	return nil
+}
+
+// Tenants returns the names of the configured tenants, sorted.
+func (m *ClientManager) Tenants() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return sortedTenantNames(m.tenants)
+}
+
+func sortedTenantNames(tenants map[string]TenantConfig) []string {
+	names := make([]string, 0, len(tenants))
+	for name := range tenants {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
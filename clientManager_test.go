@@ -0,0 +1,218 @@
+package tango
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// tenantServer issues a new token per client ID on every token request and
+// reports which token each API request used.
+type tenantServer struct {
+	tokens   atomic.Int32
+	failAuth atomic.Bool
+}
+
+func (s *tenantServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/oauth/token" {
+		_ = r.ParseForm()
+		if s.failAuth.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		n := s.tokens.Add(1)
+		_, _ = fmt.Fprintf(w, `{"access_token":"%s-%d","expires_in":3600,"token_type":"Bearer"}`, r.Form.Get("client_id"), n)
+		return
+	}
+	_, _ = fmt.Fprintf(w, `{"accountIdentifier":"%s","displayName":"%s"}`,
+		strings.TrimPrefix(r.URL.Path, "/accounts/"), r.Header.Get("Authorization"))
+}
+
+func newTenantServer(t *testing.T) (*tenantServer, string) {
+	t.Helper()
+
+	handler := &tenantServer{}
+	server := httptest.NewServer(handler)
+	originalResolver := tokenURLResolver
+	tokenURLResolver = func(string) string { return server.URL + "/oauth/token" }
+	t.Cleanup(func() {
+		tokenURLResolver = originalResolver
+		server.Close()
+	})
+	return handler, server.URL
+}
+
+func TestClientManager(t *testing.T) {
+	_, url := newTenantServer(t)
+	manager, err := NewClientManager(map[string]TenantConfig{
+		"retail": {ClientID: "retail", ClientSecret: "s1", Environment: "sandbox", AccountIdentifier: "retail-ops", BaseURL: url},
+		"hr":     {ClientID: "hr", ClientSecret: "s2", Environment: "sandbox", AccountIdentifier: "hr-ops", SendEmail: true, BaseURL: url},
+	})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	if names := manager.Tenants(); strings.Join(names, ",") != "hr,retail" {
+		t.Fatalf("unexpected tenants: %v", names)
+	}
+
+	// Concurrent callers share one client and one token
+	clients := make([]*TangoClient, 8)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clients[i], _ = manager.Client("retail")
+			_, _ = clients[i].GetAccountInfo("x")
+		}(i)
+	}
+	wg.Wait()
+	for _, client := range clients[1:] {
+		if client != clients[0] {
+			t.Fatal("expected every caller to get the same client")
+		}
+	}
+
+	retail := clients[0]
+	hr, _ := manager.Client("hr")
+	if retail.AccountIdentifier != "retail-ops" || !hr.SendEmail || retail.SendEmail {
+		t.Fatalf("unexpected client settings: %+v, %+v", retail, hr)
+	}
+
+	account, err := hr.GetAccountInfo("hr-ops")
+	if err != nil || !strings.HasPrefix(account.DisplayName, "Bearer hr-") {
+		t.Fatalf("expected the hr tenant's token, got %+v, %v", account, err)
+	}
+	account, _ = retail.GetAccountInfo("retail-ops")
+	if account.DisplayName != "Bearer retail-1" {
+		t.Fatalf("expected the retail tenant's first token to be reused, got %q", account.DisplayName)
+	}
+
+	if _, err := manager.Client("finance"); !errors.Is(err, ErrUnknownTenant) {
+		t.Fatalf("expected ErrUnknownTenant, got %v", err)
+	}
+}
+
+func TestClientManager_Reload(t *testing.T) {
+	_, url := newTenantServer(t)
+	retailConfig := TenantConfig{ClientID: "retail", ClientSecret: "s1", Environment: "sandbox", AccountIdentifier: "retail-ops", BaseURL: url}
+	hrConfig := TenantConfig{ClientID: "hr", ClientSecret: "s2", Environment: "sandbox", AccountIdentifier: "hr-ops", BaseURL: url}
+	manager, err := NewClientManager(map[string]TenantConfig{"retail": retailConfig, "hr": hrConfig})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	retail, _ := manager.Client("retail")
+	hr, _ := manager.Client("hr")
+
+	// An invalid config leaves everything as it was
+	err = manager.Reload(map[string]TenantConfig{"retail": retailConfig, "hr": {ClientID: "hr"}})
+	if err == nil || !strings.Contains(err.Error(), "tenant hr") {
+		t.Fatalf("expected a validation error for hr, got %v", err)
+	}
+	if client, _ := manager.Client("hr"); client != hr {
+		t.Fatal("expected a failed reload to keep the existing client")
+	}
+
+	hrConfig.AccountIdentifier = "hr-payroll"
+	if err := manager.Reload(map[string]TenantConfig{"hr": hrConfig}); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+
+	updated, _ := manager.Client("hr")
+	if updated == hr || updated.AccountIdentifier != "hr-payroll" {
+		t.Fatalf("expected a new client for the changed tenant, got %+v", updated)
+	}
+	if _, err := manager.Client("retail"); !errors.Is(err, ErrUnknownTenant) {
+		t.Fatalf("expected the removed tenant to be unknown, got %v", err)
+	}
+
+	// Clients handed out before the reload keep working
+	if _, err := retail.GetAccountInfo("retail-ops"); err != nil {
+		t.Fatalf("old retail client: %v", err)
+	}
+	if hr.AccountIdentifier != "hr-ops" {
+		t.Fatalf("expected the old hr client to keep its settings, got %q", hr.AccountIdentifier)
+	}
+}
+
+func TestClientManager_TransferStore(t *testing.T) {
+	_, url := newTenantServer(t)
+	config := TenantConfig{ClientID: "hr", ClientSecret: "s2", Environment: "sandbox", AccountIdentifier: "hr-ops", BaseURL: url}
+	manager, err := NewClientManager(map[string]TenantConfig{"hr": config})
+	if err != nil {
+		t.Fatalf("new manager: %v", err)
+	}
+	stores := map[string]TransferStore{"hr": NewMemoryTransferStore()}
+	manager.TransferStore = func(tenant string) TransferStore { return stores[tenant] }
+
+	before, _ := manager.Client("hr")
+	config.AccountIdentifier = "hr-payroll"
+	if err := manager.Reload(map[string]TenantConfig{"hr": config}); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	after, _ := manager.Client("hr")
+	if after == before || after.TransferStore != stores["hr"] || before.TransferStore != stores["hr"] {
+		t.Fatal("expected the replaced client to keep the tenant's transfer store")
+	}
+}
+
+func TestRefreshingTokenSource(t *testing.T) {
+	server, _ := newTenantServer(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	originalNow := timeNow
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = originalNow }()
+
+	source := &RefreshingTokenSource{ClientID: "retail", ClientSecret: "secret", Environment: "sandbox"}
+	token := func() string {
+		t.Helper()
+		value, err := source.Token()
+		if err != nil {
+			t.Fatalf("token: %v", err)
+		}
+		return value
+	}
+
+	if first := token(); first != "retail-1" || token() != "retail-1" {
+		t.Fatalf("expected the first token to be cached, got %q", first)
+	}
+
+	// Inside the leeway before expiry a new token is fetched
+	now = now.Add(56 * time.Minute)
+	if got := token(); got != "retail-2" {
+		t.Fatalf("expected a refreshed token, got %q", got)
+	}
+
+	// A failed refresh falls back to the current token until it expires
+	server.failAuth.Store(true)
+	now = now.Add(57 * time.Minute)
+	if got := token(); got != "retail-2" {
+		t.Fatalf("expected the current token while refresh fails, got %q", got)
+	}
+	now = now.Add(5 * time.Minute)
+	if _, err := source.Token(); err == nil {
+		t.Fatal("expected an error once the token has expired")
+	}
+
+	// The error reaches the caller of an API method
+	client := &TangoClient{BaseURL: "http://127.0.0.1:0", TokenSource: source}
+	if _, err := client.GetAccountInfo("x"); err == nil || !strings.Contains(err.Error(), "get token") {
+		t.Fatalf("expected a token error, got %v", err)
+	}
+}
+
+func TestTenantConfig_String(t *testing.T) {
+	config := TenantConfig{ClientID: "retail", ClientSecret: "topsecret", ServiceAccountPassword: "hunter2", Environment: "sandbox"}
+	for _, out := range []string{config.String(), fmt.Sprintf("%#v", config), fmt.Sprintf("%v", config)} {
+		if strings.Contains(out, "topsecret") || strings.Contains(out, "hunter2") || !strings.Contains(out, "retail") {
+			t.Fatalf("expected secrets to be masked, got %s", out)
+		}
+	}
+}
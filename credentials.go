@@ -0,0 +1,198 @@
+package tango
+
+import (
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+/*
+RewardCredentials holds the credentials of an embedded reward, classified by type.
+
+The struct holds the real values; String, GoString and LogValue mask them so a
+stray log line or %v does not leak a usable card.
+*/
+type RewardCredentials struct {
+	CardNumber    string
+	PIN           string
+	RedemptionURL string
+	SecurityCode  string
+	Barcode       string
+	Expiration    string
+	// Other keeps every credential that did not match a known type.
+	Other []CredentialList
+}
+
+type credentialKind int
+
+const (
+	credentialUnknown credentialKind = iota
+	credentialCardNumber
+	credentialPIN
+	credentialRedemptionURL
+	credentialSecurityCode
+	credentialBarcode
+	credentialExpiration
+)
+
+// credentialKinds maps normalized credentialType values and labels to their kind.
+var credentialKinds = map[string]credentialKind{
+	"cardnumber":     credentialCardNumber,
+	"number":         credentialCardNumber,
+	"giftcardnumber": credentialCardNumber,
+	"pin":            credentialPIN,
+	"pincode":        credentialPIN,
+	"redemptionurl":  credentialRedemptionURL,
+	"redemptionlink": credentialRedemptionURL,
+	"url":            credentialRedemptionURL,
+	"link":           credentialRedemptionURL,
+	"securitycode":   credentialSecurityCode,
+	"cvv":            credentialSecurityCode,
+	"cvc":            credentialSecurityCode,
+	"eventnumber":    credentialSecurityCode,
+	"barcode":        credentialBarcode,
+	"barcodenumber":  credentialBarcode,
+	"barcodevalue":   credentialBarcode,
+	"expiration":     credentialExpiration,
+	"expirationdate": credentialExpiration,
+	"expirydate":     credentialExpiration,
+	"expires":        credentialExpiration,
+}
+
+/*
+TypedCredentials classifies the reward's credentials into a RewardCredentials.
+
+Entries in CredentialList are matched on CredentialType, falling back to the
+Label. Entries in the Credentials map that were not already covered by
+CredentialList are matched on their key. Anything unrecognized, or a second
+value for a type that is already filled, is kept in Other.
+*/
+func (r Reward) TypedCredentials() RewardCredentials {
+	var creds RewardCredentials
+	seen := make(map[string]bool, len(r.CredentialList))
+
+	for _, entry := range r.CredentialList {
+		seen[entry.Label] = true
+		kind := classifyCredential(entry.CredentialType)
+		if kind == credentialUnknown {
+			kind = classifyCredential(entry.Label)
+		}
+		if !creds.set(kind, entry.Value) {
+			creds.Other = append(creds.Other, entry)
+		}
+	}
+
+	for _, label := range sortedKeys(r.Credentials) {
+		if seen[label] {
+			continue
+		}
+		value := r.Credentials[label]
+		if !creds.set(classifyCredential(label), value) {
+			creds.Other = append(creds.Other, CredentialList{Label: label, Value: value})
+		}
+	}
+
+	return creds
+}
+
+// set stores value in the field for kind, reporting false if kind is unknown
+// or the field already holds a value.
+func (c *RewardCredentials) set(kind credentialKind, value string) bool {
+	var field *string
+	switch kind {
+	case credentialCardNumber:
+		field = &c.CardNumber
+	case credentialPIN:
+		field = &c.PIN
+	case credentialRedemptionURL:
+		field = &c.RedemptionURL
+	case credentialSecurityCode:
+		field = &c.SecurityCode
+	case credentialBarcode:
+		field = &c.Barcode
+	case credentialExpiration:
+		field = &c.Expiration
+	default:
+		return false
+	}
+	if *field != "" {
+		return false
+	}
+	*field = value
+	return true
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func classifyCredential(name string) credentialKind {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return credentialKinds[b.String()]
+}
+
+// String returns the credentials with secrets masked. Card and barcode numbers
+// keep their last four digits, redemption URLs keep only their host, and the
+// expiration date is shown as is.
+func (c RewardCredentials) String() string {
+	var parts []string
+	add := func(name, value string) {
+		if value != "" {
+			parts = append(parts, name+": "+value)
+		}
+	}
+
+	add("CardNumber", maskCredential(c.CardNumber, 4))
+	add("PIN", maskCredential(c.PIN, 0))
+	add("RedemptionURL", maskURL(c.RedemptionURL))
+	add("SecurityCode", maskCredential(c.SecurityCode, 0))
+	add("Barcode", maskCredential(c.Barcode, 4))
+	add("Expiration", c.Expiration)
+	for _, other := range c.Other {
+		add(other.Label, maskCredential(other.Value, 0))
+	}
+
+	return "RewardCredentials{" + strings.Join(parts, ", ") + "}"
+}
+
+func (c RewardCredentials) GoString() string {
+	return c.String()
+}
+
+func (c RewardCredentials) LogValue() slog.Value {
+	return slog.StringValue(c.String())
+}
+
+func maskCredential(value string, visible int) string {
+	if value == "" {
+		return ""
+	}
+	runes := []rune(value)
+	if len(runes) <= visible*2 {
+		visible = 0
+	}
+	return strings.Repeat("*", len(runes)-visible) + string(runes[len(runes)-visible:])
+}
+
+func maskURL(value string) string {
+	if value == "" {
+		return ""
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" {
+		return maskCredential(value, 0)
+	}
+	return fmt.Sprintf("%s://%s/***", parsed.Scheme, parsed.Host)
+}
@@ -0,0 +1,66 @@
+package tango
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReward_TypedCredentials(t *testing.T) {
+	reward := Reward{
+		Credentials: map[string]string{
+			"Card Number":     "6006491234567890",
+			"PIN":             "4321",
+			"Expiration Date": "2030-01-01",
+			"Event Code":      "XYZ",
+		},
+		CredentialList: []CredentialList{
+			{Label: "Card Number", Value: "6006491234567890", Type: "text", CredentialType: "cardNumber"},
+			{Label: "PIN", Value: "4321", Type: "text", CredentialType: "pin"},
+			{Label: "Redeem", Value: "https://rewards.example.com/r/secret-token", Type: "url", CredentialType: "redemptionUrl"},
+			{Label: "Barcode", Value: "9876543210", Type: "barcode", CredentialType: "barcode"},
+			{Label: "Security Code", Value: "987", Type: "text", CredentialType: "securityCode"},
+			{Label: "Mystery", Value: "abc", Type: "text", CredentialType: "somethingNew"},
+		},
+	}
+
+	creds := reward.TypedCredentials()
+	if creds.CardNumber != "6006491234567890" || creds.PIN != "4321" || creds.SecurityCode != "987" {
+		t.Fatalf("unexpected credentials: %#v", []string{creds.CardNumber, creds.PIN, creds.SecurityCode})
+	}
+	if creds.RedemptionURL != "https://rewards.example.com/r/secret-token" || creds.Barcode != "9876543210" {
+		t.Fatalf("unexpected url/barcode: %s %s", creds.RedemptionURL, creds.Barcode)
+	}
+	if creds.Expiration != "2030-01-01" {
+		t.Fatalf("expected expiration from credentials map, got %q", creds.Expiration)
+	}
+
+	others := map[string]string{}
+	for _, other := range creds.Other {
+		others[other.Label] = other.Value
+	}
+	if len(others) != 2 || others["Mystery"] != "abc" || others["Event Code"] != "XYZ" {
+		t.Fatalf("unexpected unknown credentials: %v", creds.Other)
+	}
+}
+
+func TestRewardCredentials_StringMasksSecrets(t *testing.T) {
+	creds := RewardCredentials{
+		CardNumber:    "6006491234567890",
+		PIN:           "4321",
+		RedemptionURL: "https://rewards.example.com/r/secret-token",
+		Expiration:    "2030-01-01",
+		Other:         []CredentialList{{Label: "Event Code", Value: "XYZ"}},
+	}
+
+	for _, out := range []string{creds.String(), fmt.Sprintf("%v", creds), fmt.Sprintf("%#v", creds)} {
+		for _, secret := range []string{"600649123456", "4321", "secret-token", "XYZ"} {
+			if strings.Contains(out, secret) {
+				t.Fatalf("output leaks %q: %s", secret, out)
+			}
+		}
+		if !strings.Contains(out, "7890") || !strings.Contains(out, "rewards.example.com") || !strings.Contains(out, "2030-01-01") {
+			t.Fatalf("output hides too much: %s", out)
+		}
+	}
+}
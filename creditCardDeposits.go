@@ -0,0 +1,110 @@
+package tango
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Statuses reported for a credit card deposit.
+const (
+	DepositStatusPending = "PENDING"
+	DepositStatusSuccess = "SUCCESS"
+	DepositStatusFailed  = "FAILED"
+)
+
+type CreditCardDepositRequest struct {
+	CustomerIdentifier string  `json:"customerIdentifier"`
+	AccountIdentifier  string  `json:"accountIdentifier"`
+	CreditCardToken    string  `json:"creditCardToken"`
+	Amount             float64 `json:"amount"`
+}
+
+// CreditCardDeposit is a deposit made to an account from a registered credit
+// card. Amount is what was credited to the account; AmountCharged is what was
+// charged to the card, including the fee.
+type CreditCardDeposit struct {
+	ReferenceDepositID string  `json:"referenceDepositID"`
+	AccountNumber      string  `json:"accountNumber"`
+	Amount             float64 `json:"amount"`
+	AmountCharged      float64 `json:"amountCharged"`
+	FeePercent         float64 `json:"feePercent"`
+	Status             string  `json:"status"`
+	CreatedDate        string  `json:"createdDate"`
+}
+
+/*
+Fund an account from a registered credit card. A declined card is returned as
+an *APIError carrying Tango's error details.
+https://developers.tangocard.com/reference/addfundstoaccount
+*/
+func (c *TangoClient) CreateCreditCardDeposit(data CreditCardDepositRequest) (CreditCardDeposit, error) {
+	if data.CustomerIdentifier == "" || data.AccountIdentifier == "" {
+		return CreditCardDeposit{}, fmt.Errorf("customerIdentifier and accountIdentifier are required")
+	}
+	if data.CreditCardToken == "" {
+		return CreditCardDeposit{}, fmt.Errorf("creditCardToken is required")
+	}
+	if data.Amount <= 0 {
+		return CreditCardDeposit{}, fmt.Errorf("amount must be greater than zero")
+	}
+
+	url := c.apiURL() + "/creditCardDeposits"
+
+	payloadJSON, err := json.Marshal(data)
+	if err != nil {
+		return CreditCardDeposit{}, err
+	}
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(payloadJSON).
+		Post(url)
+	if err != nil {
+		return CreditCardDeposit{}, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if err := ensureSuccessStatus(resp, "create credit card deposit"); err != nil {
+		return CreditCardDeposit{}, withErrorDetails(err)
+	}
+
+	var responseData CreditCardDeposit
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return CreditCardDeposit{}, err
+	}
+
+	return responseData, nil
+}
+
+/*
+Get the status of a credit card deposit.
+https://developers.tangocard.com/reference/getdepositdetails
+*/
+func (c *TangoClient) GetCreditCardDeposit(depositID string) (CreditCardDeposit, error) {
+	if depositID == "" {
+		return CreditCardDeposit{}, fmt.Errorf("depositID is required")
+	}
+
+	url := c.apiURL() + "/creditCardDeposits/" + depositID
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		Get(url)
+	if err != nil {
+		return CreditCardDeposit{}, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if err := ensureSuccessStatus(resp, "get credit card deposit"); err != nil {
+		return CreditCardDeposit{}, withErrorDetails(err)
+	}
+
+	var responseData CreditCardDeposit
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return CreditCardDeposit{}, err
+	}
+
+	return responseData, nil
+}
@@ -0,0 +1,73 @@
+package tango
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestCreditCardDeposits(t *testing.T) {
+	var sent CreditCardDepositRequest
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("missing bearer token on %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "POST /creditCardDeposits":
+			_ = json.NewDecoder(r.Body).Decode(&sent)
+			if sent.CreditCardToken == "declined" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"requestId":"req-1","httpCode":400,"errors":[{"path":"creditCardToken","message":"card declined"}]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"referenceDepositID":"RAD-1","accountNumber":"A1","amount":100,"amountCharged":103.5,"feePercent":3.5,"status":"PENDING","createdDate":"2024-01-01T00:00:00Z"}`))
+		case "GET /creditCardDeposits/RAD-1":
+			_, _ = w.Write([]byte(`{"referenceDepositID":"RAD-1","amount":100,"amountCharged":103.5,"feePercent":3.5,"status":"SUCCESS"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client := &TangoClient{Token: "token"}
+
+	deposit, err := client.CreateCreditCardDeposit(CreditCardDepositRequest{
+		CustomerIdentifier: "cust",
+		AccountIdentifier:  "acct",
+		CreditCardToken:    "tok-1",
+		Amount:             100,
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if deposit.ReferenceDepositID != "RAD-1" || deposit.AmountCharged != 103.5 || deposit.Status != DepositStatusPending {
+		t.Fatalf("unexpected deposit: %+v", deposit)
+	}
+	if sent.AccountIdentifier != "acct" || sent.Amount != 100 {
+		t.Fatalf("unexpected request body: %+v", sent)
+	}
+
+	deposit, err = client.GetCreditCardDeposit("RAD-1")
+	if err != nil || deposit.Status != DepositStatusSuccess {
+		t.Fatalf("unexpected deposit: %+v, %v", deposit, err)
+	}
+
+	_, err = client.CreateCreditCardDeposit(CreditCardDepositRequest{
+		CustomerIdentifier: "cust",
+		AccountIdentifier:  "acct",
+		CreditCardToken:    "declined",
+		Amount:             100,
+	})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest || apiErr.RequestID != "req-1" {
+		t.Fatalf("expected an APIError for the decline, got %v", err)
+	}
+	if len(apiErr.Errors) != 1 || apiErr.Errors[0].Message != "card declined" || apiErr.Temporary() {
+		t.Fatalf("unexpected error details: %+v", apiErr)
+	}
+
+	if _, err := client.CreateCreditCardDeposit(CreditCardDepositRequest{CustomerIdentifier: "cust", AccountIdentifier: "acct", CreditCardToken: "tok-1"}); err == nil {
+		t.Fatal("expected an error for a zero amount")
+	}
+}
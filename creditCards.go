@@ -0,0 +1,303 @@
+package tango
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// CreditCardNumber holds the card details used to register a card. String,
+// GoString and LogValue mask them, leaving only the last four digits.
+type CreditCardNumber struct {
+	Number             string `json:"number"`
+	Expiration         string `json:"expiration"` // YYYY-MM
+	VerificationNumber string `json:"verificationNumber"`
+}
+
+func (n CreditCardNumber) String() string {
+	var parts []string
+	add := func(name, value string) {
+		if value != "" {
+			parts = append(parts, name+": "+value)
+		}
+	}
+
+	add("Number", maskCredential(n.Number, 4))
+	add("Expiration", n.Expiration)
+	add("VerificationNumber", maskCredential(n.VerificationNumber, 0))
+
+	return "CreditCardNumber{" + strings.Join(parts, ", ") + "}"
+}
+
+func (n CreditCardNumber) GoString() string {
+	return n.String()
+}
+
+func (n CreditCardNumber) LogValue() slog.Value {
+	return slog.StringValue(n.String())
+}
+
+// RegisterCreditCardRequest registers a card for funding an account. The
+// billing contact and address are those of the cardholder.
+type RegisterCreditCardRequest struct {
+	CustomerIdentifier string
+	AccountIdentifier  string
+	Label              string
+	IPAddress          string
+	Card               CreditCardNumber
+	FirstName          string
+	LastName           string
+	Email              string
+	BillingAddress     Address
+}
+
+// registerCreditCardPayload is the body Tango expects for POST /creditCards.
+type registerCreditCardPayload struct {
+	CustomerIdentifier string                `json:"customerIdentifier"`
+	AccountIdentifier  string                `json:"accountIdentifier"`
+	Label              string                `json:"label"`
+	IPAddress          string                `json:"ipAddress"`
+	CreditCard         CreditCardNumber      `json:"creditCard"`
+	BillingAddress     creditCardBillingInfo `json:"billingAddress"`
+}
+
+type creditCardBillingInfo struct {
+	FirstName    string `json:"firstName"`
+	LastName     string `json:"lastName"`
+	EmailAddress string `json:"emailAddress"`
+	AddressLine1 string `json:"addressLine1"`
+	AddressLine2 string `json:"addressLine2,omitempty"`
+	City         string `json:"city"`
+	State        string `json:"state"`
+	PostalCode   string `json:"postalCode"`
+	Country      string `json:"country"`
+}
+
+type UnregisterCreditCardRequest struct {
+	CustomerIdentifier string `json:"customerIdentifier"`
+	AccountIdentifier  string `json:"accountIdentifier"`
+	Token              string `json:"creditCardToken"`
+}
+
+// CreditCard is a registered card. Token is what deposits are made against;
+// String, GoString and LogValue mask it along with the card number.
+type CreditCard struct {
+	Token              string `json:"token"`
+	Label              string `json:"label"`
+	LastFourDigits     string `json:"lastFourDigits"`
+	ExpirationDate     string `json:"expirationDate"`
+	Status             string `json:"status"`
+	CustomerIdentifier string `json:"customerIdentifier"`
+	AccountIdentifier  string `json:"accountIdentifier"`
+	CreatedDate        string `json:"createdDate"`
+	ActivationDate     string `json:"activationDate"`
+}
+
+func (c CreditCard) String() string {
+	return fmt.Sprintf("CreditCard{Token: %s, Label: %s, Number: ************%s, Expiration: %s, Status: %s}",
+		maskCredential(c.Token, 4), c.Label, c.LastFourDigits, c.ExpirationDate, c.Status)
+}
+
+func (c CreditCard) GoString() string {
+	return c.String()
+}
+
+func (c CreditCard) LogValue() slog.Value {
+	return slog.StringValue(c.String())
+}
+
+// redactCardNumber masks the card number and verification number anywhere
+// Tango echoes them back in an *APIError.
+func redactCardNumber(err error, card CreditCardNumber) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+
+	var pairs []string
+	if card.Number != "" {
+		pairs = append(pairs, card.Number, maskCredential(card.Number, 4))
+	}
+	if card.VerificationNumber != "" {
+		pairs = append(pairs, card.VerificationNumber, maskCredential(card.VerificationNumber, 0))
+	}
+	if len(pairs) == 0 {
+		return err
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	redacted := *apiErr
+	redacted.message = replacer.Replace(apiErr.Error())
+	redacted.Body = replacer.Replace(apiErr.Body)
+	redacted.Errors = make([]APIErrorDetail, len(apiErr.Errors))
+	for i, detail := range apiErr.Errors {
+		detail.InvalidValue = replacer.Replace(detail.InvalidValue)
+		detail.Message = replacer.Replace(detail.Message)
+		redacted.Errors[i] = detail
+	}
+	return &redacted
+}
+
+type UnregisterCreditCardResponse struct {
+	CreditCardToken string `json:"creditCardToken"`
+	Status          string `json:"status"`
+}
+
+/*
+Register a credit card for funding an account.
+https://developers.tangocard.com/reference/registercreditcard
+*/
+func (c *TangoClient) RegisterCreditCard(data RegisterCreditCardRequest) (CreditCard, error) {
+	if data.CustomerIdentifier == "" || data.AccountIdentifier == "" {
+		return CreditCard{}, fmt.Errorf("customerIdentifier and accountIdentifier are required")
+	}
+	if data.Card.Number == "" || data.Card.Expiration == "" {
+		return CreditCard{}, fmt.Errorf("card number and expiration are required")
+	}
+
+	url := c.apiURL() + "/creditCards"
+
+	address := data.BillingAddress
+	payloadJSON, err := json.Marshal(registerCreditCardPayload{
+		CustomerIdentifier: data.CustomerIdentifier,
+		AccountIdentifier:  data.AccountIdentifier,
+		Label:              data.Label,
+		IPAddress:          data.IPAddress,
+		CreditCard:         data.Card,
+		BillingAddress: creditCardBillingInfo{
+			FirstName:    data.FirstName,
+			LastName:     data.LastName,
+			EmailAddress: data.Email,
+			AddressLine1: address.StreetLine1,
+			AddressLine2: address.StreetLine2,
+			City:         address.City,
+			State:        address.StateOrProvince,
+			PostalCode:   address.PostalCode,
+			Country:      address.Country,
+		},
+	})
+	if err != nil {
+		return CreditCard{}, err
+	}
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(payloadJSON).
+		Post(url)
+	if err != nil {
+		return CreditCard{}, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if err := ensureSuccessStatus(resp, "register credit card"); err != nil {
+		return CreditCard{}, redactCardNumber(withErrorDetails(err), data.Card)
+	}
+
+	var responseData CreditCard
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return CreditCard{}, err
+	}
+
+	return responseData, nil
+}
+
+/*
+Get a list of the credit cards registered on the platform.
+https://developers.tangocard.com/reference/listcreditcards
+*/
+func (c *TangoClient) ListCreditCards() ([]CreditCard, error) {
+	url := c.apiURL() + "/creditCards"
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if err := ensureSuccessStatus(resp, "list credit cards"); err != nil {
+		return nil, err
+	}
+
+	var responseData []CreditCard
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return nil, err
+	}
+
+	return responseData, nil
+}
+
+/*
+Get details for a registered credit card.
+https://developers.tangocard.com/reference/getcreditcard
+*/
+func (c *TangoClient) GetCreditCard(token string) (CreditCard, error) {
+	if token == "" {
+		return CreditCard{}, fmt.Errorf("token is required")
+	}
+
+	url := c.apiURL() + "/creditCards/" + token
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		Get(url)
+	if err != nil {
+		return CreditCard{}, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if err := ensureSuccessStatus(resp, "get credit card"); err != nil {
+		return CreditCard{}, err
+	}
+
+	var responseData CreditCard
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return CreditCard{}, err
+	}
+
+	return responseData, nil
+}
+
+/*
+Unregister a credit card so it can no longer be used for deposits.
+https://developers.tangocard.com/reference/unregistercreditcard
+*/
+func (c *TangoClient) UnregisterCreditCard(data UnregisterCreditCardRequest) (UnregisterCreditCardResponse, error) {
+	if data.CustomerIdentifier == "" || data.AccountIdentifier == "" || data.Token == "" {
+		return UnregisterCreditCardResponse{}, fmt.Errorf("customerIdentifier, accountIdentifier and token are required")
+	}
+
+	url := c.apiURL() + "/creditCardUnregisters"
+
+	payloadJSON, err := json.Marshal(data)
+	if err != nil {
+		return UnregisterCreditCardResponse{}, err
+	}
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(payloadJSON).
+		Post(url)
+	if err != nil {
+		return UnregisterCreditCardResponse{}, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if err := ensureSuccessStatus(resp, "unregister credit card"); err != nil {
+		return UnregisterCreditCardResponse{}, err
+	}
+
+	var responseData UnregisterCreditCardResponse
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return UnregisterCreditCardResponse{}, err
+	}
+
+	return responseData, nil
+}
@@ -0,0 +1,113 @@
+package tango
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCreditCards(t *testing.T) {
+	var registered map[string]interface{}
+	var unregistered UnregisterCreditCardRequest
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /creditCards":
+			_ = json.NewDecoder(r.Body).Decode(&registered)
+			_, _ = w.Write([]byte(`{"token":"cc-token-1234","label":"Ops card","lastFourDigits":"4242","expirationDate":"2030-01","status":"ACTIVE"}`))
+		case "GET /creditCards":
+			_, _ = w.Write([]byte(`[{"token":"cc-token-1234","lastFourDigits":"4242"},{"token":"cc-token-5678","lastFourDigits":"0005"}]`))
+		case "GET /creditCards/cc-token-1234":
+			_, _ = w.Write([]byte(`{"token":"cc-token-1234","lastFourDigits":"4242","status":"ACTIVE"}`))
+		case "POST /creditCardUnregisters":
+			_ = json.NewDecoder(r.Body).Decode(&unregistered)
+			_, _ = w.Write([]byte(`{"creditCardToken":"cc-token-1234","status":"SUCCESS"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client := &TangoClient{Token: "token"}
+
+	request := RegisterCreditCardRequest{
+		CustomerIdentifier: "cust",
+		AccountIdentifier:  "acct",
+		Label:              "Ops card",
+		Card:               CreditCardNumber{Number: "4111111111114242", Expiration: "2030-01", VerificationNumber: "987"},
+		FirstName:          "Ann",
+		LastName:           "Lee",
+		Email:              "ann@example.com",
+		BillingAddress:     Address{StreetLine1: "1 Main St", City: "Denver", StateOrProvince: "CO", PostalCode: "80202", Country: "US"},
+	}
+	card, err := client.RegisterCreditCard(request)
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if card.Token != "cc-token-1234" || card.LastFourDigits != "4242" {
+		t.Fatalf("unexpected card: %+v", card)
+	}
+	billing, _ := registered["billingAddress"].(map[string]interface{})
+	if billing["addressLine1"] != "1 Main St" || billing["state"] != "CO" || billing["emailAddress"] != "ann@example.com" {
+		t.Fatalf("unexpected billing address: %v", registered["billingAddress"])
+	}
+	if creditCard, _ := registered["creditCard"].(map[string]interface{}); creditCard["number"] != "4111111111114242" {
+		t.Fatalf("expected the real card number to be sent, got %v", registered["creditCard"])
+	}
+
+	cards, err := client.ListCreditCards()
+	if err != nil || len(cards) != 2 {
+		t.Fatalf("unexpected cards: %v, %v", cards, err)
+	}
+
+	card, err = client.GetCreditCard("cc-token-1234")
+	if err != nil || card.Status != "ACTIVE" {
+		t.Fatalf("unexpected card: %+v, %v", card, err)
+	}
+
+	result, err := client.UnregisterCreditCard(UnregisterCreditCardRequest{CustomerIdentifier: "cust", AccountIdentifier: "acct", Token: card.Token})
+	if err != nil || result.Status != "SUCCESS" || unregistered.Token != "cc-token-1234" {
+		t.Fatalf("unexpected unregister result: %+v, %+v, %v", result, unregistered, err)
+	}
+}
+
+func TestCreditCards_Masked(t *testing.T) {
+	request := RegisterCreditCardRequest{Card: CreditCardNumber{Number: "4111111111114242", Expiration: "2030-01", VerificationNumber: "987"}}
+	card := CreditCard{Token: "cc-token-1234", LastFourDigits: "4242"}
+
+	for _, out := range []string{
+		fmt.Sprintf("%v", request),
+		fmt.Sprintf("%+v", request),
+		fmt.Sprintf("%#v", request.Card),
+		fmt.Sprint(card),
+		fmt.Sprintf("%#v", card),
+	} {
+		if strings.Contains(out, "4111111111114242") || strings.Contains(out, "987") || strings.Contains(out, "cc-token-1234") {
+			t.Fatalf("card data leaked: %s", out)
+		}
+		if !strings.Contains(out, "4242") && !strings.Contains(out, "1234") {
+			t.Fatalf("expected the last four digits to be shown: %s", out)
+		}
+	}
+}
+
+func TestRegisterCreditCard_RedactsErrors(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errors":[{"path":"creditCard.number","message":"invalid card","invalidValue":"4111111111114242"}]}`))
+	})
+	client := &TangoClient{Token: "token"}
+
+	_, err := client.RegisterCreditCard(RegisterCreditCardRequest{
+		CustomerIdentifier: "cust",
+		AccountIdentifier:  "acct",
+		Card:               CreditCardNumber{Number: "4111111111114242", Expiration: "2030-01"},
+	})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected an APIError, got %v", err)
+	}
+	if strings.Contains(err.Error(), "4111111111114242") || strings.Contains(apiErr.Body, "4111111111114242") {
+		t.Fatalf("card number leaked into the error: %v / %s", err, apiErr.Body)
+	}
+}
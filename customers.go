@@ -2,16 +2,17 @@ package tango
 
 import (
 	"encoding/json"
-
-	"github.com/go-resty/resty/v2"
+	"errors"
+	"fmt"
+	"net/http"
 )
 
 type Customer struct {
-	CustomerIdentifier string `json:"customerIdentifier"`
-	DisplayName        string `json:"displayName"`
-	Status             string `json:"status"`
-	CreatedAt          string `json:"createdAt"`
-	Accounts           []UserAccount
+	CustomerIdentifier string        `json:"customerIdentifier"`
+	DisplayName        string        `json:"displayName"`
+	Status             string        `json:"status"`
+	CreatedAt          string        `json:"createdAt"`
+	Accounts           []UserAccount `json:"accounts"`
 }
 type UserAccount struct {
 	AccountIdentifier string `json:"accountIdentifier"`
@@ -37,13 +38,12 @@ Get a list of all Customers on the Tango Platform.
 https://developers.tangocard.com/reference/listcustomers-1
 */
 func (c *TangoClient) GetCustomers() ([]Customer, error) {
-	url := ApiURL + "/customers"
+	url := c.apiURL() + "/customers"
 
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		Get(url)
 	if err != nil {
 		return nil, err
@@ -66,13 +66,12 @@ Get details for a specific Customer on the tango platform.
 https://developers.tangocard.com/reference/getcustomer-1
 */
 func (c *TangoClient) GetCustomer(customerIdentifier string) (Customer, error) {
-	url := ApiURL + "/customers/" + customerIdentifier
+	url := c.apiURL() + "/customers/" + customerIdentifier
 
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		Get(url)
 	if err != nil {
 		return Customer{}, err
@@ -95,13 +94,12 @@ Get a list of all Accounts created for a specific Customer on the tango platform
 https://developers.tangocard.com/reference/listcustomeraccounts-1
 */
 func (c *TangoClient) GetCustomerAccounts(customerIdentifier string) ([]UserAccount, error) {
-	url := ApiURL + "/customers/" + customerIdentifier + "/accounts"
+	url := c.apiURL() + "/customers/" + customerIdentifier + "/accounts"
 
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		Get(url)
 
 	if err != nil {
@@ -121,36 +119,54 @@ func (c *TangoClient) GetCustomerAccounts(customerIdentifier string) ([]UserAcco
 }
 
 /*
-Create a Customer on the Tango Platform
+Create a Customer on the Tango Platform.
 https://developers.tangocard.com/reference/createcustomer-1
+
+Deprecated: use CreateCustomerRecord, which returns the created Customer.
 */
 func (c *TangoClient) CreateCustomer(customerIdentifier string, displayName string) (CreateCustomerRequest, error) {
-	url := ApiURL + "/customers"
+	customer, err := c.CreateCustomerRecord(customerIdentifier, displayName)
+	if err != nil {
+		return CreateCustomerRequest{}, err
+	}
+
+	return CreateCustomerRequest{
+		CustomerIdentifier: customer.CustomerIdentifier,
+		DisplayName:        customer.DisplayName,
+	}, nil
+}
+
+/*
+Create a Customer on the Tango Platform and return it as created, with its
+status and creation time.
+https://developers.tangocard.com/reference/createcustomer-1
+*/
+func (c *TangoClient) CreateCustomerRecord(customerIdentifier string, displayName string) (Customer, error) {
+	url := c.apiURL() + "/customers"
 
 	payload := CreateCustomerRequest{
 		CustomerIdentifier: customerIdentifier,
 		DisplayName:        displayName,
 	}
 
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		SetBody(payload).
 		Post(url)
 
 	if err != nil {
-		return CreateCustomerRequest{}, err
+		return Customer{}, err
 	}
 	if err := ensureSuccessStatus(resp, "create customer"); err != nil {
-		return CreateCustomerRequest{}, err
+		return Customer{}, err
 	}
 
-	var responseData CreateCustomerRequest
+	var responseData Customer
 	err = json.Unmarshal(resp.Body(), &responseData)
 	if err != nil {
-		return CreateCustomerRequest{}, err
+		return Customer{}, err
 	}
 
 	return responseData, nil
@@ -159,9 +175,29 @@ func (c *TangoClient) CreateCustomer(customerIdentifier string, displayName stri
 /*
 Create an Account under a specific Customer on this Platform.
 https://developers.tangocard.com/reference/createcustomeraccount-1
+
+Deprecated: use CreateCustomerAccountRecord, which returns the created Account.
 */
 func (c *TangoClient) CreateCustomerAccount(customerIdentifier string, accountIdentifier string, displayName string, contactEmail string) (CreateCustomerAccountRequest, error) {
-	url := ApiURL + "/customers/" + customerIdentifier + "/accounts"
+	account, err := c.CreateCustomerAccountRecord(customerIdentifier, accountIdentifier, displayName, contactEmail)
+	if err != nil {
+		return CreateCustomerAccountRequest{}, err
+	}
+
+	return CreateCustomerAccountRequest{
+		AccountIdentifier: account.AccountIdentifier,
+		DisplayName:       account.DisplayName,
+		ContactEmail:      account.ContactEmail,
+	}, nil
+}
+
+/*
+Create an Account under a specific Customer on this Platform and return it as
+created, with its account number, status, creation time and balance.
+https://developers.tangocard.com/reference/createcustomeraccount-1
+*/
+func (c *TangoClient) CreateCustomerAccountRecord(customerIdentifier string, accountIdentifier string, displayName string, contactEmail string) (Account, error) {
+	url := c.apiURL() + "/customers/" + customerIdentifier + "/accounts"
 
 	payload := CreateCustomerAccountRequest{
 		AccountIdentifier: accountIdentifier,
@@ -169,26 +205,119 @@ func (c *TangoClient) CreateCustomerAccount(customerIdentifier string, accountId
 		ContactEmail:      contactEmail,
 	}
 
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		SetBody(payload).
 		Post(url)
 
 	if err != nil {
-		return CreateCustomerAccountRequest{}, err
+		return Account{}, err
 	}
 	if err := ensureSuccessStatus(resp, "create customer account"); err != nil {
-		return CreateCustomerAccountRequest{}, err
+		return Account{}, err
 	}
 
-	var responseData CreateCustomerAccountRequest
+	var responseData Account
 	err = json.Unmarshal(resp.Body(), &responseData)
 	if err != nil {
-		return CreateCustomerAccountRequest{}, err
+		return Account{}, err
 	}
 
 	return responseData, nil
 }
+
+/*
+EnsureCustomer returns the Customer with the given identifier, creating it if
+it does not exist yet. created reports whether this call created it. A create
+that loses a race with another caller, and gets Tango's 409 Conflict, counts
+as finding the existing customer.
+*/
+func (c *TangoClient) EnsureCustomer(customerIdentifier string, displayName string) (customer Customer, created bool, err error) {
+	if customerIdentifier == "" {
+		return Customer{}, false, fmt.Errorf("customerIdentifier is required")
+	}
+
+	customer, err = c.GetCustomer(customerIdentifier)
+	if err == nil {
+		return customer, false, nil
+	}
+	if !isAPIStatus(err, http.StatusNotFound) {
+		return Customer{}, false, err
+	}
+
+	customer, err = c.CreateCustomerRecord(customerIdentifier, displayName)
+	if err == nil {
+		return customer, true, nil
+	}
+	if !isAPIStatus(err, http.StatusConflict) {
+		return Customer{}, false, err
+	}
+
+	customer, err = c.GetCustomer(customerIdentifier)
+	if err != nil {
+		return Customer{}, false, err
+	}
+	return customer, false, nil
+}
+
+/*
+EnsureCustomerAccount returns the Account with the given identifier under the
+customer, creating it if it does not exist yet. created reports whether this
+call created it. As with EnsureCustomer, a 409 Conflict from the create counts
+as finding the existing account. An account identifier already used by a
+different customer is an error.
+*/
+func (c *TangoClient) EnsureCustomerAccount(customerIdentifier string, accountIdentifier string, displayName string, contactEmail string) (account Account, created bool, err error) {
+	if customerIdentifier == "" || accountIdentifier == "" {
+		return Account{}, false, fmt.Errorf("customerIdentifier and accountIdentifier are required")
+	}
+
+	exists, err := c.customerHasAccount(customerIdentifier, accountIdentifier)
+	if err != nil {
+		return Account{}, false, err
+	}
+	if !exists {
+		account, err = c.CreateCustomerAccountRecord(customerIdentifier, accountIdentifier, displayName, contactEmail)
+		if err == nil {
+			return account, true, nil
+		}
+		if !isAPIStatus(err, http.StatusConflict) {
+			return Account{}, false, err
+		}
+
+		exists, err = c.customerHasAccount(customerIdentifier, accountIdentifier)
+		if err != nil {
+			return Account{}, false, err
+		}
+		if !exists {
+			return Account{}, false, fmt.Errorf("account %q already exists under another customer", accountIdentifier)
+		}
+	}
+
+	account, err = c.GetAccountInfo(accountIdentifier)
+	if err != nil {
+		return Account{}, false, err
+	}
+	return account, false, nil
+}
+
+func (c *TangoClient) customerHasAccount(customerIdentifier string, accountIdentifier string) (bool, error) {
+	accounts, err := c.GetCustomerAccounts(customerIdentifier)
+	if err != nil {
+		return false, err
+	}
+	for _, account := range accounts {
+		if account.AccountIdentifier == accountIdentifier {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// isAPIStatus reports whether err is an *APIError with the given status code.
+func isAPIStatus(err error, statusCode int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == statusCode
+}
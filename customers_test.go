@@ -0,0 +1,178 @@
+package tango
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func customerServer(w http.ResponseWriter, r *http.Request) {
+	switch r.Method + " " + r.URL.Path {
+	case "POST /customers":
+		_, _ = w.Write([]byte(`{"customerIdentifier":"acme","displayName":"Acme","status":"ACTIVE","createdAt":"2024-01-01T00:00:00Z","accounts":[]}`))
+	case "POST /customers/acme/accounts":
+		_, _ = w.Write([]byte(`{"accountIdentifier":"ops","accountNumber":"A12-345","displayName":"Ops","currencyCode":"USD","currentBalance":0,"createdAt":"2024-01-01T00:00:00Z","status":"ACTIVE","contactEmail":"ops@example.com"}`))
+	case "GET /customers/acme":
+		_, _ = w.Write([]byte(`{"customerIdentifier":"acme","accounts":[{"accountIdentifier":"ops","accountNumber":"A12-345","status":"ACTIVE"}]}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestCreateCustomerRecord(t *testing.T) {
+	newTestServer(t, customerServer)
+	client := &TangoClient{Token: "token"}
+
+	customer, err := client.CreateCustomerRecord("acme", "Acme")
+	if err != nil || customer.Status != "ACTIVE" || customer.CreatedAt == "" {
+		t.Fatalf("unexpected customer: %+v, %v", customer, err)
+	}
+
+	account, err := client.CreateCustomerAccountRecord("acme", "ops", "Ops", "ops@example.com")
+	if err != nil {
+		t.Fatalf("create account: %v", err)
+	}
+	if account.AccountNumber != "A12-345" || account.Status != "ACTIVE" || account.CurrencyCode != "USD" || account.CreatedAt == "" {
+		t.Fatalf("unexpected account: %+v", account)
+	}
+
+	// The original methods keep their signatures
+	legacyCustomer, err := client.CreateCustomer("acme", "Acme")
+	if err != nil || legacyCustomer != (CreateCustomerRequest{CustomerIdentifier: "acme", DisplayName: "Acme"}) {
+		t.Fatalf("unexpected legacy customer: %+v, %v", legacyCustomer, err)
+	}
+	legacyAccount, err := client.CreateCustomerAccount("acme", "ops", "Ops", "ops@example.com")
+	if err != nil || legacyAccount != (CreateCustomerAccountRequest{AccountIdentifier: "ops", DisplayName: "Ops", ContactEmail: "ops@example.com"}) {
+		t.Fatalf("unexpected legacy account: %+v, %v", legacyAccount, err)
+	}
+}
+
+func TestGetCustomer_NestedAccounts(t *testing.T) {
+	newTestServer(t, customerServer)
+	client := &TangoClient{Token: "token"}
+
+	customer, err := client.GetCustomer("acme")
+	if err != nil || len(customer.Accounts) != 1 || customer.Accounts[0].AccountNumber != "A12-345" {
+		t.Fatalf("unexpected customer: %+v, %v", customer, err)
+	}
+}
+
+// provisioningServer keeps customers and accounts in memory. Identifiers in
+// raced are created by "another replica" between the lookup and the create, so
+// the create gets a 409.
+type provisioningServer struct {
+	customers map[string]bool
+	accounts  map[string]string // account -> customer
+	raced     map[string]string // identifier -> owning customer once created
+	posts     int
+}
+
+func (s *provisioningServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "customers":
+		if !s.customers[parts[1]] {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"customerIdentifier":"` + parts[1] + `","status":"ACTIVE"}`))
+	case r.Method == http.MethodPost && len(parts) == 1 && parts[0] == "customers":
+		s.posts++
+		var body CreateCustomerRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if _, ok := s.raced[body.CustomerIdentifier]; ok || s.customers[body.CustomerIdentifier] {
+			s.customers[body.CustomerIdentifier] = true
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.customers[body.CustomerIdentifier] = true
+		_, _ = w.Write([]byte(`{"customerIdentifier":"` + body.CustomerIdentifier + `","status":"ACTIVE"}`))
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[2] == "accounts":
+		var accounts []string
+		for account, customer := range s.accounts {
+			if customer == parts[1] {
+				accounts = append(accounts, `{"accountIdentifier":"`+account+`"}`)
+			}
+		}
+		_, _ = w.Write([]byte("[" + strings.Join(accounts, ",") + "]"))
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[2] == "accounts":
+		s.posts++
+		var body CreateCustomerAccountRequest
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if owner, ok := s.raced[body.AccountIdentifier]; ok {
+			s.accounts[body.AccountIdentifier] = owner
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		s.accounts[body.AccountIdentifier] = parts[1]
+		_, _ = w.Write([]byte(`{"accountIdentifier":"` + body.AccountIdentifier + `","accountNumber":"A1","status":"ACTIVE"}`))
+	case r.Method == http.MethodGet && len(parts) == 2 && parts[0] == "accounts":
+		if _, ok := s.accounts[parts[1]]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"accountIdentifier":"` + parts[1] + `","currencyCode":"USD","currentBalance":10}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestEnsureCustomer(t *testing.T) {
+	server := &provisioningServer{
+		customers: map[string]bool{"existing": true},
+		accounts:  map[string]string{},
+		raced:     map[string]string{"racing": ""},
+	}
+	newTestServer(t, server.ServeHTTP)
+	client := &TangoClient{Token: "token"}
+
+	tests := []struct {
+		id      string
+		created bool
+	}{
+		{"existing", false},
+		{"new", true},
+		{"new", false},
+		{"racing", false},
+	}
+	for _, tt := range tests {
+		customer, created, err := client.EnsureCustomer(tt.id, "Name")
+		if err != nil || created != tt.created || customer.CustomerIdentifier != tt.id {
+			t.Fatalf("%s: got %+v, created %v, %v; want created %v", tt.id, customer, created, err, tt.created)
+		}
+	}
+	if server.posts != 2 {
+		t.Fatalf("expected 2 create attempts, got %d", server.posts)
+	}
+}
+
+func TestEnsureCustomerAccount(t *testing.T) {
+	server := &provisioningServer{
+		customers: map[string]bool{"acme": true, "globex": true},
+		accounts:  map[string]string{"ops": "acme"},
+		raced:     map[string]string{"racing": "acme", "taken": "globex"},
+	}
+	newTestServer(t, server.ServeHTTP)
+	client := &TangoClient{Token: "token"}
+
+	tests := []struct {
+		id      string
+		created bool
+	}{
+		{"ops", false},
+		{"sales", true},
+		{"sales", false},
+		{"racing", false},
+	}
+	for _, tt := range tests {
+		account, created, err := client.EnsureCustomerAccount("acme", tt.id, "Name", "ops@example.com")
+		if err != nil || created != tt.created || account.AccountIdentifier != tt.id {
+			t.Fatalf("%s: got %+v, created %v, %v; want created %v", tt.id, account, created, err, tt.created)
+		}
+	}
+
+	if _, _, err := client.EnsureCustomerAccount("acme", "taken", "Name", "ops@example.com"); err == nil || !strings.Contains(err.Error(), "another customer") {
+		t.Fatalf("expected an error for an account owned by another customer, got %v", err)
+	}
+}
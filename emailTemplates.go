@@ -0,0 +1,227 @@
+package tango
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// EmailTemplate is a reward email template, referenced from an order by its Etid.
+type EmailTemplate struct {
+	Etid          string `json:"etid"`
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	FromName      string `json:"fromName"`
+	Subject       string `json:"subject"`
+	HeaderImage   string `json:"headerImage"`
+	HeaderText    string `json:"headerText"`
+	MessageText   string `json:"messageText"`
+	ClosingText   string `json:"closingText"`
+	Status        string `json:"status"`
+	CreatedAt     string `json:"createdAt"`
+	LastUpdatedAt string `json:"lastUpdatedAt"`
+}
+
+// EmailTemplateRequest is the body for creating or updating an email template.
+// On update, fields left empty are not changed.
+type EmailTemplateRequest struct {
+	Name        string `json:"name,omitempty"`
+	FromName    string `json:"fromName,omitempty"`
+	Subject     string `json:"subject,omitempty"`
+	HeaderImage string `json:"headerImage,omitempty"`
+	HeaderText  string `json:"headerText,omitempty"`
+	MessageText string `json:"messageText,omitempty"`
+	ClosingText string `json:"closingText,omitempty"`
+}
+
+type EmailTemplatesResponse struct {
+	EmailTemplates []EmailTemplate `json:"emailTemplates"`
+}
+
+/*
+Get a list of the email templates available to the platform.
+https://developers.tangocard.com/reference/listemailtemplates
+*/
+func (c *TangoClient) GetEmailTemplates() ([]EmailTemplate, error) {
+	url := c.apiURL() + "/emailTemplates"
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureSuccessStatus(resp, "get email templates"); err != nil {
+		return nil, err
+	}
+
+	var responseData EmailTemplatesResponse
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return nil, err
+	}
+
+	return responseData.EmailTemplates, nil
+}
+
+/*
+Get details for a specific email template.
+https://developers.tangocard.com/reference/getemailtemplate
+*/
+func (c *TangoClient) GetEmailTemplate(etid string) (EmailTemplate, error) {
+	if etid == "" {
+		return EmailTemplate{}, fmt.Errorf("etid is required")
+	}
+
+	url := c.apiURL() + "/emailTemplates/" + etid
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		Get(url)
+	if err != nil {
+		return EmailTemplate{}, err
+	}
+	if err := ensureSuccessStatus(resp, "get email template"); err != nil {
+		return EmailTemplate{}, err
+	}
+
+	var responseData EmailTemplate
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return EmailTemplate{}, err
+	}
+
+	return responseData, nil
+}
+
+/*
+Create an email template.
+https://developers.tangocard.com/reference/createemailtemplate
+*/
+func (c *TangoClient) CreateEmailTemplate(data EmailTemplateRequest) (EmailTemplate, error) {
+	if data.Name == "" {
+		return EmailTemplate{}, fmt.Errorf("name is required")
+	}
+
+	url := c.apiURL() + "/emailTemplates"
+
+	payloadJSON, err := json.Marshal(data)
+	if err != nil {
+		return EmailTemplate{}, err
+	}
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(payloadJSON).
+		Post(url)
+	if err != nil {
+		return EmailTemplate{}, err
+	}
+	if err := ensureSuccessStatus(resp, "create email template"); err != nil {
+		return EmailTemplate{}, err
+	}
+
+	var responseData EmailTemplate
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return EmailTemplate{}, err
+	}
+
+	c.rememberEmailTemplate(responseData.Etid)
+	return responseData, nil
+}
+
+/*
+Update an email template.
+https://developers.tangocard.com/reference/updateemailtemplate
+*/
+func (c *TangoClient) UpdateEmailTemplate(etid string, data EmailTemplateRequest) (EmailTemplate, error) {
+	if etid == "" {
+		return EmailTemplate{}, fmt.Errorf("etid is required")
+	}
+
+	url := c.apiURL() + "/emailTemplates/" + etid
+
+	payloadJSON, err := json.Marshal(data)
+	if err != nil {
+		return EmailTemplate{}, err
+	}
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(payloadJSON).
+		Patch(url)
+	if err != nil {
+		return EmailTemplate{}, err
+	}
+	if err := ensureSuccessStatus(resp, "update email template"); err != nil {
+		return EmailTemplate{}, err
+	}
+
+	var responseData EmailTemplate
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return EmailTemplate{}, err
+	}
+
+	return responseData, nil
+}
+
+// ErrUnknownEmailTemplate is returned by Order and PreviewOrder, when
+// CheckEmailTemplates is set, for an Etid that does not exist.
+var ErrUnknownEmailTemplate = errors.New("unknown email template")
+
+// checkOrderEmailTemplate confirms that an order's Etid exists when the client
+// has CheckEmailTemplates set.
+func (c *TangoClient) checkOrderEmailTemplate(data CreateOrderData) error {
+	if !c.CheckEmailTemplates || data.Etid == "" {
+		return nil
+	}
+	return c.checkEmailTemplate(data.Etid)
+}
+
+// checkEmailTemplate confirms that etid exists. Templates that have been seen
+// once are remembered, so a batch of orders looks each one up only once.
+func (c *TangoClient) checkEmailTemplate(etid string) error {
+	state := c.state()
+	state.templatesMu.Lock()
+	known := state.knownTemplates[etid]
+	state.templatesMu.Unlock()
+	if known {
+		return nil
+	}
+
+	_, err := c.GetEmailTemplate(etid)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w %q", ErrUnknownEmailTemplate, etid)
+	}
+	if err != nil {
+		return fmt.Errorf("checking email template %q: %w", etid, err)
+	}
+
+	c.rememberEmailTemplate(etid)
+	return nil
+}
+
+func (c *TangoClient) rememberEmailTemplate(etid string) {
+	if etid == "" {
+		return
+	}
+	state := c.state()
+	state.templatesMu.Lock()
+	defer state.templatesMu.Unlock()
+	if state.knownTemplates == nil {
+		state.knownTemplates = make(map[string]bool)
+	}
+	state.knownTemplates[etid] = true
+}
@@ -0,0 +1,99 @@
+package tango
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestEmailTemplates(t *testing.T) {
+	var updated map[string]interface{}
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /emailTemplates":
+			_, _ = w.Write([]byte(`{"emailTemplates":[{"etid":"E000001","name":"Default","type":"GLOBAL"}]}`))
+		case "GET /emailTemplates/E000002":
+			_, _ = w.Write([]byte(`{"etid":"E000002","name":"Thanks","subject":"Thank you"}`))
+		case "POST /emailTemplates":
+			var body EmailTemplateRequest
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			_, _ = w.Write([]byte(`{"etid":"E000003","name":"` + body.Name + `"}`))
+		case "PATCH /emailTemplates/E000002":
+			_ = json.NewDecoder(r.Body).Decode(&updated)
+			_, _ = w.Write([]byte(`{"etid":"E000002","name":"Thanks","subject":"Thanks again"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client := &TangoClient{Token: "token"}
+
+	templates, err := client.GetEmailTemplates()
+	if err != nil || len(templates) != 1 || templates[0].Type != "GLOBAL" {
+		t.Fatalf("unexpected templates: %+v, %v", templates, err)
+	}
+
+	template, err := client.GetEmailTemplate("E000002")
+	if err != nil || template.Subject != "Thank you" {
+		t.Fatalf("unexpected template: %+v, %v", template, err)
+	}
+
+	created, err := client.CreateEmailTemplate(EmailTemplateRequest{Name: "Holiday", Subject: "Happy holidays"})
+	if err != nil || created.Etid != "E000003" || created.Name != "Holiday" {
+		t.Fatalf("unexpected created template: %+v, %v", created, err)
+	}
+
+	template, err = client.UpdateEmailTemplate("E000002", EmailTemplateRequest{Subject: "Thanks again"})
+	if err != nil || template.Subject != "Thanks again" {
+		t.Fatalf("unexpected updated template: %+v, %v", template, err)
+	}
+	if len(updated) != 1 || updated["subject"] != "Thanks again" {
+		t.Fatalf("expected only the changed field to be sent, got %v", updated)
+	}
+
+	_, err = client.GetEmailTemplate("missing")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 APIError, got %v", err)
+	}
+}
+
+func TestOrder_CheckEmailTemplates(t *testing.T) {
+	var lookups, orders int
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /emailTemplates/E000001":
+			lookups++
+			_, _ = w.Write([]byte(`{"etid":"E000001"}`))
+		case "POST /orders":
+			orders++
+			_, _ = w.Write([]byte(`{"referenceOrderID":"RA1"}`))
+		default:
+			lookups++
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	client := &TangoClient{Token: "token", CheckEmailTemplates: true}
+
+	_, err := client.Order(CreateOrderData{Utid: "U1", Amount: 5, Etid: "E999999"})
+	if !errors.Is(err, ErrUnknownEmailTemplate) {
+		t.Fatalf("expected ErrUnknownEmailTemplate, got %v", err)
+	}
+	if orders != 0 {
+		t.Fatal("expected the order not to be sent")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Order(CreateOrderData{Utid: "U1", Amount: 5, Etid: "E000001"}); err != nil {
+			t.Fatalf("order: %v", err)
+		}
+	}
+	if lookups != 2 || orders != 2 {
+		t.Fatalf("expected a known template to be looked up once, got %d lookups and %d orders", lookups, orders)
+	}
+
+	client.CheckEmailTemplates = false
+	if _, err := client.Order(CreateOrderData{Utid: "U1", Amount: 5, Etid: "E999999"}); err != nil {
+		t.Fatalf("expected no check when disabled, got %v", err)
+	}
+}
@@ -2,8 +2,6 @@ package tango
 
 import (
 	"encoding/json"
-
-	"github.com/go-resty/resty/v2"
 )
 
 /*
@@ -11,7 +9,7 @@ Get a list of exchange rates.
 https://developers.tangocard.com/reference/getexchangerates-1
 */
 func (c *TangoClient) GetExchangeRates(baseCurrency, rewardCurrency string) (ExchangeRatesResponse, error) {
-	url := ApiURL + "/exchangerates"
+	url := c.apiURL() + "/exchangerates"
 
 	if baseCurrency != "" && rewardCurrency != "" {
 		url += "?baseCurrency=" + baseCurrency + "&rewardCurrency=" + rewardCurrency
@@ -21,11 +19,10 @@ func (c *TangoClient) GetExchangeRates(baseCurrency, rewardCurrency string) (Exc
 		url += "?rewardCurrency=" + rewardCurrency
 	}
 
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		Get(url)
 
 	if err != nil {
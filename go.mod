@@ -2,9 +2,16 @@ module github.com/c150pilot/go-tango-card
 
 go 1.21.0
 
-require github.com/go-resty/resty/v2 v2.7.0
+require (
+	github.com/boombuler/barcode v1.1.0
+	github.com/go-resty/resty/v2 v2.7.0
+	github.com/joho/godotenv v1.5.1
+	github.com/makiuchi-d/gozxing v0.1.1
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
-	github.com/joho/godotenv v1.5.1 // indirect
 	golang.org/x/net v0.0.0-20211029224645-99673261e6eb // indirect
+	golang.org/x/text v0.3.7 // indirect
+	golang.org/x/xerrors v0.0.0-20200804184101-5ec99f83aff1 // indirect
 )
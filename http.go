@@ -1,16 +1,67 @@
 package tango
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 
 	"github.com/go-resty/resty/v2"
 )
 
+// APIErrorDetail is a single validation or processing error reported by Tango.
+type APIErrorDetail struct {
+	Path         string `json:"path"`
+	I18NKey      string `json:"i18nKey,omitempty"`
+	Message      string `json:"message"`
+	InvalidValue string `json:"invalidValue"`
+	Constraint   string `json:"constraint"`
+}
+
+// APIError is returned when the Tango API responds with a non-2xx status.
+// Use errors.As to inspect the status code and any error details.
+type APIError struct {
+	Operation  string
+	StatusCode int
+	Status     string
+	Body       string
+	RequestID  string
+	Errors     []APIErrorDetail
+
+	// message overrides the default error text
+	message string
+}
+
+func (e *APIError) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+	return fmt.Sprintf("%s failed with status %d (%s): %s", e.Operation, e.StatusCode, e.Status, e.Body)
+}
+
+// Temporary reports whether the request may succeed if retried: rate limiting
+// and server-side errors.
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
 func ensureSuccessStatus(resp *resty.Response, operation string) error {
 	if resp.StatusCode() >= 200 && resp.StatusCode() < 300 {
 		return nil
 	}
 
-	return fmt.Errorf("%s failed with status %d (%s): %s", operation, resp.StatusCode(), resp.Status(), strings.TrimSpace(string(resp.Body())))
+	apiErr := &APIError{
+		Operation:  operation,
+		StatusCode: resp.StatusCode(),
+		Status:     resp.Status(),
+		Body:       strings.TrimSpace(string(resp.Body())),
+	}
+
+	var responseError CreateOrderResponseError
+	if err := json.Unmarshal(resp.Body(), &responseError); err == nil {
+		apiErr.RequestID = responseError.RequestId
+		apiErr.Errors = responseError.Errors
+	}
+
+	return apiErr
 }
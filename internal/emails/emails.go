@@ -0,0 +1,38 @@
+// Package emails holds the email address checks shared by tango and its
+// subpackages.
+package emails
+
+import (
+	"net/mail"
+	"sort"
+	"strings"
+)
+
+// Valid reports whether email is a bare address, without a display name.
+func Valid(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
+
+// Same reports whether a and b hold the same addresses, ignoring order, case
+// and surrounding whitespace.
+func Same(a, b []string) bool {
+	normalize := func(emails []string) []string {
+		out := make([]string, len(emails))
+		for i, email := range emails {
+			out[i] = strings.ToLower(strings.TrimSpace(email))
+		}
+		sort.Strings(out)
+		return out
+	}
+	x, y := normalize(a), normalize(b)
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+package emails
+
+import "testing"
+
+func TestValid(t *testing.T) {
+	for email, want := range map[string]bool{
+		"ops@acme.example":       true,
+		"":                       false,
+		"nope":                   false,
+		"Ops <ops@acme.example>": false,
+		" ops@acme.example":      false,
+	} {
+		if got := Valid(email); got != want {
+			t.Errorf("Valid(%q) = %v, want %v", email, got, want)
+		}
+	}
+}
+
+func TestSame(t *testing.T) {
+	if !Same([]string{"A@acme.example", " b@acme.example"}, []string{"b@acme.example", "a@acme.example "}) {
+		t.Error("expected order, case and whitespace to be ignored")
+	}
+	if Same([]string{"a@acme.example", "a@acme.example"}, []string{"a@acme.example", "b@acme.example"}) {
+		t.Error("expected duplicates to be counted")
+	}
+	if Same([]string{"a@acme.example"}, nil) {
+		t.Error("expected different lengths to differ")
+	}
+}
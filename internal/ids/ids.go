@@ -0,0 +1,16 @@
+// Package ids generates the random IDs used by the outbox and scheduler.
+package ids
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// New returns a random 24 character hex ID.
+func New() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
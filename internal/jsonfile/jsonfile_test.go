@@ -0,0 +1,32 @@
+package jsonfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "items.json")
+
+	if err := Write(path, []string{"a"}); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := Write(path, []string{"b"}); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "[\n  \"b\"\n]" {
+		t.Fatalf("unexpected file %q, %v", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("expected the temporary file to be gone, got %d entries", len(entries))
+	}
+
+	if err := Write(filepath.Join(dir, "missing", "items.json"), nil); err == nil {
+		t.Fatal("expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,36 @@
+// Package retry decides whether a failed order is worth trying again, and
+// when.
+package retry
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+// Retryable reports whether an order that failed with err may succeed later:
+// rate limiting, server errors and failures to reach the API. Anything else,
+// such as a rejected or invalid order, will fail the same way every time.
+func Retryable(err error) bool {
+	var apiErr *tango.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// Backoff waits 10 seconds after the first failure and doubles with each
+// attempt, up to 30 minutes.
+func Backoff(attempt int) time.Duration {
+	delay := 10 * time.Second
+	for i := 1; i < attempt && delay < 30*time.Minute; i++ {
+		delay *= 2
+	}
+	if delay > 30*time.Minute {
+		delay = 30 * time.Minute
+	}
+	return delay
+}
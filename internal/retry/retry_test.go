@@ -0,0 +1,35 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&tango.APIError{StatusCode: 503}, true},
+		{&tango.APIError{StatusCode: 429}, true},
+		{fmt.Errorf("order: %w", &tango.APIError{StatusCode: 400}), false},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{errors.New("insufficient funds"), false},
+	}
+	for _, tt := range tests {
+		if got := Retryable(tt.err); got != tt.want {
+			t.Errorf("Retryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	if Backoff(1) != 10*time.Second || Backoff(3) != 40*time.Second || Backoff(20) != 30*time.Minute {
+		t.Fatalf("unexpected backoff: %v, %v, %v", Backoff(1), Backoff(3), Backoff(20))
+	}
+}
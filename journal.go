@@ -0,0 +1,248 @@
+package tango
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// JournalOutcome is the state of an order attempt recorded in a Journal.
+type JournalOutcome string
+
+const (
+	// JournalPending is written before the order is sent.
+	JournalPending JournalOutcome = "pending"
+	// JournalSucceeded means the order was placed.
+	JournalSucceeded JournalOutcome = "succeeded"
+	// JournalFailed means Tango rejected the order, or Recover found it was never placed.
+	JournalFailed JournalOutcome = "failed"
+	// JournalUnknown means the request was sent but its result is not known,
+	// for example after a network error or a server error.
+	JournalUnknown JournalOutcome = "unknown"
+)
+
+// JournalEntry records one step of an order attempt.
+type JournalEntry struct {
+	ExternalRefID    string         `json:"externalRefID,omitempty"`
+	PayloadHash      string         `json:"payloadHash"`
+	Outcome          JournalOutcome `json:"outcome"`
+	ReferenceOrderID string         `json:"referenceOrderID,omitempty"`
+	Error            string         `json:"error,omitempty"`
+	Time             time.Time      `json:"time"`
+}
+
+/*
+Journal durably records order attempts. Order appends a JournalPending entry
+before sending a request and a second entry with the outcome once it has a
+response, so a crash in between leaves a pending entry behind for Recover.
+
+Append must not return until the entry is durable.
+*/
+type Journal interface {
+	Append(entry JournalEntry) error
+	Entries() ([]JournalEntry, error)
+}
+
+func hashPayload(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+func completedJournalEntry(entry JournalEntry, resp CreateOrderResponse, err error) JournalEntry {
+	entry.Time = timeNow()
+	entry.ReferenceOrderID = resp.ReferenceOrderID
+
+	var apiErr *APIError
+	switch {
+	case err == nil:
+		entry.Outcome = JournalSucceeded
+	case errors.As(err, &apiErr) && !apiErr.Temporary():
+		entry.Outcome = JournalFailed
+		entry.Error = err.Error()
+	default:
+		entry.Outcome = JournalUnknown
+		entry.Error = err.Error()
+	}
+	return entry
+}
+
+// InDoubt returns the attempts whose latest entry is pending or unknown, i.e.
+// those that may or may not have placed an order.
+func InDoubt(entries []JournalEntry) []JournalEntry {
+	latest := make(map[string]int)
+	var order []string
+	for i, entry := range entries {
+		key := entry.ExternalRefID + "\x00" + entry.PayloadHash
+		if _, ok := latest[key]; !ok {
+			order = append(order, key)
+		}
+		latest[key] = i
+	}
+
+	var inDoubt []JournalEntry
+	for _, key := range order {
+		entry := entries[latest[key]]
+		if entry.Outcome == JournalPending || entry.Outcome == JournalUnknown {
+			inDoubt = append(inDoubt, entry)
+		}
+	}
+	return inDoubt
+}
+
+/*
+Recover resolves every in-doubt attempt in the journal and appends the result.
+
+An attempt with a referenceOrderID is confirmed with GetOrder. Otherwise the
+orders placed with its externalRefID are listed: if one exists the attempt is
+marked succeeded, and if none does it is marked failed, meaning it is safe to
+send again. Attempts without an externalRefID cannot be looked up and are
+returned unchanged, as are attempts whose lookup failed.
+
+Recover returns the entries it appended and any attempts it could not resolve.
+Run it at startup, before placing new orders.
+*/
+func (c *TangoClient) Recover(journal Journal) (resolved []JournalEntry, unresolved []JournalEntry, err error) {
+	entries, err := journal.Entries()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, entry := range InDoubt(entries) {
+		result, ok := c.resolveJournalEntry(entry)
+		if !ok {
+			unresolved = append(unresolved, entry)
+			continue
+		}
+		if err := journal.Append(result); err != nil {
+			return resolved, unresolved, err
+		}
+		resolved = append(resolved, result)
+	}
+
+	return resolved, unresolved, nil
+}
+
+func (c *TangoClient) resolveJournalEntry(entry JournalEntry) (JournalEntry, bool) {
+	entry.Time = timeNow()
+	entry.Error = ""
+
+	if entry.ReferenceOrderID != "" {
+		if _, err := c.GetOrder(entry.ReferenceOrderID); err != nil {
+			return entry, false
+		}
+		entry.Outcome = JournalSucceeded
+		return entry, true
+	}
+
+	if entry.ExternalRefID == "" {
+		return entry, false
+	}
+	orders, err := c.FindOrdersByExternalRefID(entry.ExternalRefID)
+	if err != nil {
+		return entry, false
+	}
+	if len(orders) == 0 {
+		entry.Outcome = JournalFailed
+		entry.Error = "recovered: no order was placed"
+		return entry, true
+	}
+	entry.Outcome = JournalSucceeded
+	entry.ReferenceOrderID = orders[0].ReferenceOrderID
+	return entry, true
+}
+
+// FileJournal is an append-only Journal stored as JSON lines in a file.
+// Each Append is synced to disk before it returns.
+type FileJournal struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// OpenFileJournal opens or creates a journal file. A torn final line, left by
+// a crash in the middle of a write, is removed.
+func OpenFileJournal(path string) (*FileJournal, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := truncateTornLine(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &FileJournal{file: file}, nil
+}
+
+// truncateTornLine cuts the file back to just after its last newline.
+func truncateTornLine(file *os.File) error {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return err
+	}
+	if len(data) == 0 || data[len(data)-1] == '\n' {
+		return nil
+	}
+	if err := file.Truncate(int64(bytes.LastIndexByte(data, '\n') + 1)); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// Append writes an entry and syncs it to disk.
+func (j *FileJournal) Append(entry JournalEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Write(line); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// Entries reads every entry in the journal.
+func (j *FileJournal) Entries() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(j.file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var entry JournalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("journal line %d is corrupt: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Close closes the journal file.
+func (j *FileJournal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
@@ -0,0 +1,153 @@
+package tango
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOrder_WritesJournal(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			ExternalRefID string `json:"externalRefID"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch body.ExternalRefID {
+		case "ok":
+			_, _ = w.Write([]byte(`{"referenceOrderID":"RA1","status":"COMPLETE"}`))
+		case "rejected":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":[{"path":"utid","message":"bad"}]}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	})
+
+	journal, err := OpenFileJournal(filepath.Join(t.TempDir(), "orders.journal"))
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+	defer journal.Close()
+
+	client := &TangoClient{Token: "token", AccountIdentifier: "acct", Journal: journal}
+	order := CreateOrderData{CustomerIdentifier: "cust", Utid: "U1", Amount: 5, DeliveryMethod: DeliveryMethodEmbedded}
+
+	for _, ref := range []string{"ok", "rejected", "flaky"} {
+		order.ExternalRefID = ref
+		_, _ = client.Order(order)
+	}
+
+	entries, err := journal.Entries()
+	if err != nil {
+		t.Fatalf("read journal: %v", err)
+	}
+	want := []JournalOutcome{JournalPending, JournalSucceeded, JournalPending, JournalFailed, JournalPending, JournalUnknown}
+	if len(entries) != len(want) {
+		t.Fatalf("expected %d entries, got %+v", len(want), entries)
+	}
+	for i, entry := range entries {
+		if entry.Outcome != want[i] {
+			t.Fatalf("entry %d: expected %s, got %+v", i, want[i], entry)
+		}
+		if entry.PayloadHash == "" || entry.PayloadHash != entries[i-i%2].PayloadHash {
+			t.Fatalf("entry %d: payload hash missing or inconsistent", i)
+		}
+	}
+	if entries[1].ReferenceOrderID != "RA1" {
+		t.Fatalf("expected referenceOrderID on success entry, got %+v", entries[1])
+	}
+
+	inDoubt := InDoubt(entries)
+	if len(inDoubt) != 1 || inDoubt[0].ExternalRefID != "flaky" {
+		t.Fatalf("expected only the flaky order in doubt, got %+v", inDoubt)
+	}
+}
+
+func TestRecover(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/orders/RA9":
+			_, _ = w.Write([]byte(`{"referenceOrderID":"RA9","status":"COMPLETE"}`))
+		case r.URL.Path == "/orders" && r.URL.Query().Get("externalRefID") == "placed":
+			_, _ = w.Write([]byte(`{"orders":[{"referenceOrderID":"RA2","externalRefID":"placed"}]}`))
+		case r.URL.Path == "/orders" && r.URL.Query().Get("externalRefID") == "lost":
+			_, _ = w.Write([]byte(`{"orders":[]}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	journal, err := OpenFileJournal(filepath.Join(t.TempDir(), "orders.journal"))
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+	defer journal.Close()
+
+	for _, entry := range []JournalEntry{
+		{ExternalRefID: "done", PayloadHash: "h0", Outcome: JournalPending},
+		{ExternalRefID: "done", PayloadHash: "h0", Outcome: JournalSucceeded, ReferenceOrderID: "RA0"},
+		{ExternalRefID: "placed", PayloadHash: "h1", Outcome: JournalPending},
+		{ExternalRefID: "lost", PayloadHash: "h2", Outcome: JournalUnknown},
+		{ExternalRefID: "known", PayloadHash: "h3", Outcome: JournalUnknown, ReferenceOrderID: "RA9"},
+		{PayloadHash: "h4", Outcome: JournalPending},
+		{ExternalRefID: "down", PayloadHash: "h5", Outcome: JournalPending},
+	} {
+		if err := journal.Append(entry); err != nil {
+			t.Fatalf("append: %v", err)
+		}
+	}
+
+	client := &TangoClient{Token: "token"}
+	resolved, unresolved, err := client.Recover(journal)
+	if err != nil {
+		t.Fatalf("recover: %v", err)
+	}
+
+	outcomes := map[string]JournalEntry{}
+	for _, entry := range resolved {
+		outcomes[entry.ExternalRefID] = entry
+	}
+	if len(resolved) != 3 ||
+		outcomes["placed"].Outcome != JournalSucceeded || outcomes["placed"].ReferenceOrderID != "RA2" ||
+		outcomes["lost"].Outcome != JournalFailed ||
+		outcomes["known"].Outcome != JournalSucceeded {
+		t.Fatalf("unexpected resolutions: %+v", resolved)
+	}
+	if len(unresolved) != 2 {
+		t.Fatalf("expected 2 unresolved entries, got %+v", unresolved)
+	}
+
+	entries, _ := journal.Entries()
+	if len(InDoubt(entries)) != 2 {
+		t.Fatalf("expected resolutions to be written back, got %+v", InDoubt(entries))
+	}
+}
+
+func TestFileJournal_TornLastLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.journal")
+	content := `{"externalRefID":"a","payloadHash":"h","outcome":"pending","time":"2026-01-01T00:00:00Z"}` + "\n" + `{"externalRefID":"a","payl`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	journal, err := OpenFileJournal(path)
+	if err != nil {
+		t.Fatalf("open journal: %v", err)
+	}
+	defer journal.Close()
+
+	entries, err := journal.Entries()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("expected torn line to be dropped, got %+v, %v", entries, err)
+	}
+
+	if err := journal.Append(JournalEntry{ExternalRefID: "b", PayloadHash: "h", Outcome: JournalPending}); err != nil {
+		t.Fatalf("append: %v", err)
+	}
+	entries, err = journal.Entries()
+	if err != nil || len(entries) != 2 || entries[1].ExternalRefID != "b" {
+		t.Fatalf("expected append after torn line to be readable, got %+v, %v", entries, err)
+	}
+}
@@ -1,9 +1,12 @@
 package tango
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"time"
 
-	"github.com/go-resty/resty/v2"
+	"github.com/c150pilot/go-tango-card/internal/emails"
 )
 
 /*
@@ -103,13 +106,12 @@ Get a list of Line Items placed under this Platform.
 https://developers.tangocard.com/reference/listlineitems
 */
 func (c *TangoClient) GetLineItems() (LineItemsResponse, error) {
-	url := ApiURL + "/lineItems"
+	url := c.apiURL() + "/lineItems"
 
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		Get(url)
 
 	if err != nil {
@@ -133,13 +135,17 @@ Get details for a specific line item.
 https://developers.tangocard.com/reference/getlineitem
 */
 func (c *TangoClient) GetLineItem(lineItemID string) (LineItem, error) {
-	url := ApiURL + "/lineItems/" + lineItemID
+	return c.getLineItem(context.Background(), lineItemID)
+}
+
+func (c *TangoClient) getLineItem(ctx context.Context, lineItemID string) (LineItem, error) {
+	url := c.apiURL() + "/lineItems/" + lineItemID
 
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	resp, err := client.R().
+		SetContext(ctx).
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		Get(url)
 
 	if err != nil {
@@ -163,15 +169,77 @@ Resend a specific Line Item.
 https://developers.tangocard.com/reference/resendlineitem
 */
 func (c *TangoClient) ResendLineItem(lineItemID string) (ResendResponse, error) {
-	url := ApiURL + "/lineItems/" + lineItemID + "/resends"
+	if lineItemID == "" {
+		return ResendResponse{}, fmt.Errorf("lineItemID is required")
+	}
+	return c.resendLineItem(lineItemID, ResendLineItemOptions{})
+}
 
-	client := resty.New()
+// ResendLineItemOptions controls how a line item is resent.
+type ResendLineItemOptions struct {
+	// NewEmail sends the reward to a corrected address instead of the original one.
+	NewEmail string
+}
 
-	resp, err := client.R().
-		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
-		Post(url)
+// ResendLineItemRequest is the body sent to the resend endpoint.
+type ResendLineItemRequest struct {
+	NewEmail string `json:"newEmail,omitempty"`
+}
+
+// DefaultResendCooldown is used when TangoClient.ResendCooldown is zero.
+const DefaultResendCooldown = 10 * time.Minute
+
+// ResendCooldownError is returned when a line item is resent again before the
+// client's ResendCooldown has passed.
+type ResendCooldownError struct {
+	LineItemID string
+	RetryAfter time.Duration
+}
+
+func (e *ResendCooldownError) Error() string {
+	return fmt.Sprintf("line item %s was resent recently; retry in %s", e.LineItemID, e.RetryAfter.Round(time.Second))
+}
+
+/*
+ResendLineItemWithOptions resends a specific Line Item, optionally to a corrected
+email address.
+
+Resends of the same line item are limited to one per ResendCooldown (default
+DefaultResendCooldown) per client; a repeat within the window returns a
+*ResendCooldownError without contacting Tango. A negative ResendCooldown
+disables the check. ResendLineItem is not subject to the cooldown.
+https://developers.tangocard.com/reference/resendlineitem
+*/
+func (c *TangoClient) ResendLineItemWithOptions(lineItemID string, opts ResendLineItemOptions) (ResendResponse, error) {
+	if lineItemID == "" {
+		return ResendResponse{}, fmt.Errorf("lineItemID is required")
+	}
+	if opts.NewEmail != "" && !emails.Valid(opts.NewEmail) {
+		return ResendResponse{}, fmt.Errorf("invalid email address %q", opts.NewEmail)
+	}
+	if err := c.reserveResend(lineItemID); err != nil {
+		return ResendResponse{}, err
+	}
 
+	response, err := c.resendLineItem(lineItemID, opts)
+	if err != nil {
+		c.releaseResend(lineItemID)
+	}
+	return response, err
+}
+
+func (c *TangoClient) resendLineItem(lineItemID string, opts ResendLineItemOptions) (ResendResponse, error) {
+	url := c.apiURL() + "/lineItems/" + lineItemID + "/resends"
+
+	client := c.newHTTPClient()
+
+	request := client.R().
+		SetHeader("Content-Type", "application/json")
+	if opts.NewEmail != "" {
+		request.SetBody(ResendLineItemRequest{NewEmail: opts.NewEmail})
+	}
+
+	resp, err := request.Post(url)
 	if err != nil {
 		return ResendResponse{}, err
 	}
@@ -188,9 +256,159 @@ func (c *TangoClient) ResendLineItem(lineItemID string) (ResendResponse, error)
 	return responseData, nil
 }
 
+// reserveResend records a resend of lineItemID, or returns a *ResendCooldownError
+// if the previous one is still inside the cooldown window. Resends whose window
+// has passed are forgotten.
+func (c *TangoClient) reserveResend(lineItemID string) error {
+	cooldown := c.ResendCooldown
+	if cooldown == 0 {
+		cooldown = DefaultResendCooldown
+	}
+	if cooldown < 0 {
+		return nil
+	}
+
+	state := c.state()
+	state.resendMu.Lock()
+	defer state.resendMu.Unlock()
+
+	now := timeNow()
+	for id, last := range state.lastResends {
+		if now.Sub(last) >= cooldown {
+			delete(state.lastResends, id)
+		}
+	}
+	if last, ok := state.lastResends[lineItemID]; ok {
+		return &ResendCooldownError{LineItemID: lineItemID, RetryAfter: cooldown - now.Sub(last)}
+	}
+	if state.lastResends == nil {
+		state.lastResends = make(map[string]time.Time)
+	}
+	state.lastResends[lineItemID] = now
+	return nil
+}
+
+// releaseResend forgets a resend that did not go through, so it can be retried straight away.
+func (c *TangoClient) releaseResend(lineItemID string) {
+	state := c.state()
+	state.resendMu.Lock()
+	defer state.resendMu.Unlock()
+	delete(state.lastResends, lineItemID)
+}
+
 type ResendResponse struct {
 	Id        string `json:"id"`
 	LegacyID  string `json:"legacyId"`
 	CreatedAt string `json:"createdAt"`
 	Email     string `json:"email"`
 }
+
+// LineItemActionRequest is the optional body sent with a line item lifecycle action.
+type LineItemActionRequest struct {
+	Note string `json:"note,omitempty"`
+}
+
+// ReissueLineItemRequest is the body sent to reissue a line item to a new recipient.
+type ReissueLineItemRequest struct {
+	Recipient Person `json:"recipient"`
+	Note      string `json:"note,omitempty"`
+}
+
+// LineItemActionResponse is the response to a line item lifecycle action.
+type LineItemActionResponse struct {
+	ReferenceLineItemID string `json:"referenceLineItemID"`
+	Status              string `json:"status"`
+	CreatedAt           string `json:"createdAt"`
+	// ReissuedLineItemID is set when reissuing and identifies the replacement line item.
+	ReissuedLineItemID string `json:"reissuedLineItemID,omitempty"`
+}
+
+// LineItemActionResult pairs the response to a lifecycle action with the line
+// item as it stands after the action. Fetching the line item is best effort:
+// if it fails, the action has still been done and LineItem is left empty.
+type LineItemActionResult struct {
+	LineItemActionResponse
+	LineItem LineItem
+}
+
+/*
+Cancel a specific Line Item. Cancelled rewards can no longer be redeemed.
+https://developers.tangocard.com/reference/cancellineitem
+*/
+func (c *TangoClient) CancelLineItem(lineItemID string, note string) (LineItemActionResult, error) {
+	return c.lineItemAction(lineItemID, "cancel", LineItemActionRequest{Note: note})
+}
+
+/*
+Freeze a specific Line Item so it cannot be redeemed until it is unfrozen.
+https://developers.tangocard.com/reference/freezelineitem
+*/
+func (c *TangoClient) FreezeLineItem(lineItemID string, note string) (LineItemActionResult, error) {
+	return c.lineItemAction(lineItemID, "freeze", LineItemActionRequest{Note: note})
+}
+
+/*
+Unfreeze a specific Line Item that was previously frozen.
+https://developers.tangocard.com/reference/unfreezelineitem
+*/
+func (c *TangoClient) UnfreezeLineItem(lineItemID string, note string) (LineItemActionResult, error) {
+	return c.lineItemAction(lineItemID, "unfreeze", LineItemActionRequest{Note: note})
+}
+
+/*
+Reissue a specific Line Item to a new recipient. The original reward is cancelled
+and a replacement is sent to the new recipient.
+https://developers.tangocard.com/reference/reissuelineitem
+*/
+func (c *TangoClient) ReissueLineItem(lineItemID string, recipient Person, note string) (LineItemActionResult, error) {
+	if recipient.Email == "" {
+		return LineItemActionResult{}, fmt.Errorf("recipient email is required")
+	}
+	if !emails.Valid(recipient.Email) {
+		return LineItemActionResult{}, fmt.Errorf("invalid recipient email address %q", recipient.Email)
+	}
+	return c.lineItemAction(lineItemID, "reissue", ReissueLineItemRequest{Recipient: recipient, Note: note})
+}
+
+// lineItemAction posts a lifecycle action for a line item and then fetches the
+// line item again so the caller sees its updated status. Once the action has
+// succeeded no error is returned, so callers do not retry a cancel or reissue
+// that went through.
+func (c *TangoClient) lineItemAction(lineItemID string, action string, body interface{}) (LineItemActionResult, error) {
+	if lineItemID == "" {
+		return LineItemActionResult{}, fmt.Errorf("lineItemID is required")
+	}
+
+	url := c.apiURL() + "/lineItems/" + lineItemID + "/" + action
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(body).
+		Post(url)
+
+	if err != nil {
+		return LineItemActionResult{}, err
+	}
+	if err := ensureSuccessStatus(resp, action+" line item"); err != nil {
+		return LineItemActionResult{}, err
+	}
+
+	var result LineItemActionResult
+	if len(resp.Body()) > 0 {
+		err = json.Unmarshal(resp.Body(), &result.LineItemActionResponse)
+		if err != nil {
+			return LineItemActionResult{}, err
+		}
+	}
+
+	if lineItem, err := c.GetLineItem(lineItemID); err == nil {
+		result.LineItem = lineItem
+	}
+	if result.Status == "" {
+		result.Status = result.LineItem.Status
+	}
+
+	return result, nil
+}
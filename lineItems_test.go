@@ -0,0 +1,239 @@
+package tango
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestServer points ApiURL at handler for the duration of the test.
+func newTestServer(t *testing.T, handler http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	originalURL := ApiURL
+	ApiURL = server.URL
+	t.Cleanup(func() {
+		ApiURL = originalURL
+		server.Close()
+	})
+	return server
+}
+
+func lineItemServer(t *testing.T, status *string, calls *[]string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("missing bearer token")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		*calls = append(*calls, r.Method+" "+r.URL.Path)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/lineItems/RA1":
+			_, _ = w.Write([]byte(`{"referenceLineItemID":"RA1","status":"` + *status + `"}`))
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/lineItems/RA1/"):
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			action := strings.TrimPrefix(r.URL.Path, "/lineItems/RA1/")
+			switch action {
+			case "freeze":
+				*status = "FROZEN"
+			case "unfreeze", "reissue":
+				*status = "COMPLETE"
+			case "cancel":
+				*status = "CANCELLED"
+			}
+			if action == "reissue" {
+				recipient, _ := body["recipient"].(map[string]interface{})
+				if recipient["email"] != "new@example.com" {
+					t.Errorf("unexpected reissue body: %v", body)
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				_, _ = w.Write([]byte(`{"referenceLineItemID":"RA1","reissuedLineItemID":"RA2","createdAt":"2026-01-01T00:00:00Z"}`))
+				return
+			}
+			if body["note"] != "fraud report" && action == "freeze" {
+				t.Errorf("expected note in freeze body, got %v", body)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"referenceLineItemID":"RA1","status":"` + *status + `"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"httpCode":404}`))
+		}
+	}
+}
+
+func TestLineItemLifecycle(t *testing.T) {
+	status := "COMPLETE"
+	var calls []string
+	newTestServer(t, lineItemServer(t, &status, &calls))
+
+	client := &TangoClient{Token: "token"}
+
+	result, err := client.FreezeLineItem("RA1", "fraud report")
+	if err != nil {
+		t.Fatalf("freeze failed: %v", err)
+	}
+	if result.Status != "FROZEN" || result.LineItem.Status != "FROZEN" {
+		t.Fatalf("unexpected freeze result: %+v", result)
+	}
+
+	result, err = client.UnfreezeLineItem("RA1", "")
+	if err != nil || result.LineItem.Status != "COMPLETE" {
+		t.Fatalf("unexpected unfreeze result: %+v, %v", result, err)
+	}
+
+	result, err = client.ReissueLineItem("RA1", Person{FirstName: "New", Email: "new@example.com"}, "wrong recipient")
+	if err != nil {
+		t.Fatalf("reissue failed: %v", err)
+	}
+	if result.ReissuedLineItemID != "RA2" || result.Status != "COMPLETE" {
+		t.Fatalf("unexpected reissue result: %+v", result)
+	}
+
+	result, err = client.CancelLineItem("RA1", "")
+	if err != nil || result.LineItem.Status != "CANCELLED" {
+		t.Fatalf("unexpected cancel result: %+v, %v", result, err)
+	}
+
+	want := []string{
+		"POST /lineItems/RA1/freeze", "GET /lineItems/RA1",
+		"POST /lineItems/RA1/unfreeze", "GET /lineItems/RA1",
+		"POST /lineItems/RA1/reissue", "GET /lineItems/RA1",
+		"POST /lineItems/RA1/cancel", "GET /lineItems/RA1",
+	}
+	if strings.Join(calls, ",") != strings.Join(want, ",") {
+		t.Fatalf("unexpected calls: %v", calls)
+	}
+}
+
+func TestLineItemLifecycle_Errors(t *testing.T) {
+	status := "COMPLETE"
+	var calls []string
+	newTestServer(t, lineItemServer(t, &status, &calls))
+
+	client := &TangoClient{Token: "token"}
+
+	if _, err := client.CancelLineItem("", ""); err == nil {
+		t.Fatalf("expected error for missing line item ID")
+	}
+	if _, err := client.ReissueLineItem("RA1", Person{Email: "bad"}, ""); err == nil {
+		t.Fatalf("expected error for invalid email")
+	}
+	_, err := client.FreezeLineItem("RA404", "")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("expected 404 error, got %v", err)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("expected only the failing request to reach the server, got %v", calls)
+	}
+}
+
+func TestLineItemLifecycle_StatusUnavailable(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"referenceLineItemID":"RA1","reissuedLineItemID":"RA2"}`))
+	})
+
+	client := &TangoClient{Token: "token"}
+
+	// The reissue went through, so it must not look like a failure
+	result, err := client.ReissueLineItem("RA1", Person{Email: "new@example.com"}, "")
+	if err != nil {
+		t.Fatalf("expected no error when only the status fetch fails, got %v", err)
+	}
+	if result.ReissuedLineItemID != "RA2" || result.LineItem.ReferenceLineItemID != "" {
+		t.Fatalf("expected the action response without a line item, got %+v", result)
+	}
+}
+
+func TestResendLineItemWithOptions(t *testing.T) {
+	var bodies []string
+	fail := false
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, "/lineItems/RA") || !strings.HasSuffix(r.URL.Path, "/resends") {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		buf := new(strings.Builder)
+		_, _ = io.Copy(buf, r.Body)
+		bodies = append(bodies, buf.String())
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"RS1","createdAt":"2026-01-01T00:00:00Z","email":"fixed@example.com"}`))
+	})
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	originalNow := timeNow
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = originalNow }()
+
+	client := &TangoClient{Token: "token", ResendCooldown: time.Hour}
+
+	if _, err := client.ResendLineItemWithOptions("RA1", ResendLineItemOptions{NewEmail: "not-an-email"}); err == nil {
+		t.Fatalf("expected invalid email error")
+	}
+
+	resp, err := client.ResendLineItemWithOptions("RA1", ResendLineItemOptions{NewEmail: "fixed@example.com"})
+	if err != nil {
+		t.Fatalf("resend failed: %v", err)
+	}
+	if resp.Email != "fixed@example.com" || !strings.Contains(bodies[0], `"newEmail":"fixed@example.com"`) {
+		t.Fatalf("unexpected resend: %+v, body %s", resp, bodies[0])
+	}
+
+	now = now.Add(20 * time.Minute)
+	_, err = client.ResendLineItemWithOptions("RA1", ResendLineItemOptions{})
+	var cooldownErr *ResendCooldownError
+	if !errors.As(err, &cooldownErr) || cooldownErr.RetryAfter != 40*time.Minute {
+		t.Fatalf("expected cooldown error with 40m remaining, got %v", err)
+	}
+	if len(bodies) != 1 {
+		t.Fatalf("cooldown should block the request, got %d requests", len(bodies))
+	}
+
+	// ResendLineItem keeps its original behavior and is not held back
+	if _, err := client.ResendLineItem("RA1"); err != nil {
+		t.Fatalf("plain resend should ignore the cooldown, got %v", err)
+	}
+
+	now = now.Add(41 * time.Minute)
+	fail = true
+	if _, err := client.ResendLineItemWithOptions("RA1", ResendLineItemOptions{}); err == nil {
+		t.Fatalf("expected upstream error")
+	}
+	fail = false
+	if _, err := client.ResendLineItemWithOptions("RA2", ResendLineItemOptions{}); err != nil {
+		t.Fatalf("resend of another line item failed: %v", err)
+	}
+	if _, err := client.ResendLineItemWithOptions("RA1", ResendLineItemOptions{}); err != nil {
+		t.Fatalf("failed resend should not start a cooldown, got %v", err)
+	}
+
+	// Entries are dropped once their window has passed
+	now = now.Add(2 * time.Hour)
+	if _, err := client.ResendLineItemWithOptions("RA3", ResendLineItemOptions{}); err != nil {
+		t.Fatalf("resend RA3: %v", err)
+	}
+	if n := len(client.state().lastResends); n != 1 {
+		t.Fatalf("expected only the latest resend to be remembered, got %d", n)
+	}
+	if bodies[1] != "" {
+		t.Fatalf("plain resend should post an empty body, got %q", bodies[1])
+	}
+}
@@ -0,0 +1,232 @@
+package tango
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/c150pilot/go-tango-card/internal/emails"
+	"github.com/go-resty/resty/v2"
+)
+
+// LowBalanceAlert emails EmailsToNotify when an account's balance drops below
+// BalanceThreshold.
+type LowBalanceAlert struct {
+	BalanceAlertID   string   `json:"balanceAlertID"`
+	BalanceThreshold float64  `json:"balanceThreshold"`
+	EmailsToNotify   []string `json:"emailsToNotify"`
+	CreatedDate      string   `json:"createdDate"`
+}
+
+type LowBalanceAlertRequest struct {
+	BalanceThreshold float64  `json:"balanceThreshold"`
+	EmailsToNotify   []string `json:"emailsToNotify"`
+}
+
+/*
+Get the low balance alerts set up for an account.
+https://developers.tangocard.com/reference/getlowbalancealerts
+*/
+func (c *TangoClient) GetLowBalanceAlerts(accountIdentifier string) ([]LowBalanceAlert, error) {
+	if accountIdentifier == "" {
+		return nil, fmt.Errorf("accountIdentifier is required")
+	}
+
+	url := c.apiURL() + "/accounts/" + accountIdentifier + "/lowbalance"
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if err := ensureSuccessStatus(resp, "get low balance alerts"); err != nil {
+		return nil, err
+	}
+
+	var responseData []LowBalanceAlert
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return nil, err
+	}
+
+	return responseData, nil
+}
+
+/*
+Set up a low balance alert for an account.
+https://developers.tangocard.com/reference/createlowbalancealert
+*/
+func (c *TangoClient) CreateLowBalanceAlert(accountIdentifier string, data LowBalanceAlertRequest) (LowBalanceAlert, error) {
+	if accountIdentifier == "" {
+		return LowBalanceAlert{}, fmt.Errorf("accountIdentifier is required")
+	}
+	if err := data.validate(); err != nil {
+		return LowBalanceAlert{}, err
+	}
+
+	url := c.apiURL() + "/accounts/" + accountIdentifier + "/lowbalance"
+
+	return c.sendLowBalanceAlert(url, data, "create low balance alert", resty.MethodPost)
+}
+
+/*
+Change the threshold or recipients of a low balance alert.
+https://developers.tangocard.com/reference/updatelowbalancealert
+*/
+func (c *TangoClient) UpdateLowBalanceAlert(accountIdentifier, balanceAlertID string, data LowBalanceAlertRequest) (LowBalanceAlert, error) {
+	if accountIdentifier == "" || balanceAlertID == "" {
+		return LowBalanceAlert{}, fmt.Errorf("accountIdentifier and balanceAlertID are required")
+	}
+	if err := data.validate(); err != nil {
+		return LowBalanceAlert{}, err
+	}
+
+	url := c.apiURL() + "/accounts/" + accountIdentifier + "/lowbalance/" + balanceAlertID
+
+	return c.sendLowBalanceAlert(url, data, "update low balance alert", resty.MethodPatch)
+}
+
+/*
+Delete a low balance alert.
+https://developers.tangocard.com/reference/deletelowbalancealert
+*/
+func (c *TangoClient) DeleteLowBalanceAlert(accountIdentifier, balanceAlertID string) error {
+	if accountIdentifier == "" || balanceAlertID == "" {
+		return fmt.Errorf("accountIdentifier and balanceAlertID are required")
+	}
+
+	url := c.apiURL() + "/accounts/" + accountIdentifier + "/lowbalance/" + balanceAlertID
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		Delete(url)
+	if err != nil {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	return ensureSuccessStatus(resp, "delete low balance alert")
+}
+
+func (c *TangoClient) sendLowBalanceAlert(url string, data LowBalanceAlertRequest, operation, method string) (LowBalanceAlert, error) {
+	payloadJSON, err := json.Marshal(data)
+	if err != nil {
+		return LowBalanceAlert{}, err
+	}
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(payloadJSON).
+		Execute(method, url)
+	if err != nil {
+		return LowBalanceAlert{}, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if err := ensureSuccessStatus(resp, operation); err != nil {
+		return LowBalanceAlert{}, withErrorDetails(err)
+	}
+
+	var responseData LowBalanceAlert
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return LowBalanceAlert{}, err
+	}
+
+	return responseData, nil
+}
+
+func (r LowBalanceAlertRequest) validate() error {
+	if r.BalanceThreshold <= 0 {
+		return fmt.Errorf("balanceThreshold must be greater than zero")
+	}
+	if len(r.EmailsToNotify) == 0 {
+		return fmt.Errorf("emailsToNotify needs at least one address")
+	}
+	for _, email := range r.EmailsToNotify {
+		if !emails.Valid(email) {
+			return fmt.Errorf("invalid email address %q", email)
+		}
+	}
+	return nil
+}
+
+// LowBalanceAlertSync reports what SyncLowBalanceAlerts changed.
+type LowBalanceAlertSync struct {
+	Created   []LowBalanceAlert
+	Updated   []LowBalanceAlert
+	Deleted   []LowBalanceAlert
+	Unchanged []LowBalanceAlert
+}
+
+// Changed reports whether the sync made any change.
+func (s LowBalanceAlertSync) Changed() bool {
+	return len(s.Created)+len(s.Updated)+len(s.Deleted) > 0
+}
+
+/*
+SyncLowBalanceAlerts makes an account's low balance alerts match desired.
+Alerts are matched by threshold: a desired threshold with no alert is created,
+an alert whose recipients differ is updated, and alerts at thresholds that are
+not desired (or duplicates of one that is) are deleted. Recipients are
+compared without regard to order or case.
+
+If a change fails, the returned result lists the changes made before it.
+*/
+func (c *TangoClient) SyncLowBalanceAlerts(accountIdentifier string, desired []LowBalanceAlertRequest) (LowBalanceAlertSync, error) {
+	var result LowBalanceAlertSync
+
+	wanted := make(map[float64]LowBalanceAlertRequest, len(desired))
+	for _, alert := range desired {
+		if err := alert.validate(); err != nil {
+			return result, err
+		}
+		if _, dup := wanted[alert.BalanceThreshold]; dup {
+			return result, fmt.Errorf("threshold %v is listed more than once", alert.BalanceThreshold)
+		}
+		wanted[alert.BalanceThreshold] = alert
+	}
+
+	existing, err := c.GetLowBalanceAlerts(accountIdentifier)
+	if err != nil {
+		return result, err
+	}
+
+	matched := make(map[float64]bool, len(existing))
+	for _, alert := range existing {
+		want, ok := wanted[alert.BalanceThreshold]
+		if !ok || matched[alert.BalanceThreshold] {
+			if err := c.DeleteLowBalanceAlert(accountIdentifier, alert.BalanceAlertID); err != nil {
+				return result, err
+			}
+			result.Deleted = append(result.Deleted, alert)
+			continue
+		}
+		matched[alert.BalanceThreshold] = true
+
+		if emails.Same(alert.EmailsToNotify, want.EmailsToNotify) {
+			result.Unchanged = append(result.Unchanged, alert)
+			continue
+		}
+		updated, err := c.UpdateLowBalanceAlert(accountIdentifier, alert.BalanceAlertID, want)
+		if err != nil {
+			return result, err
+		}
+		result.Updated = append(result.Updated, updated)
+	}
+
+	for _, want := range desired {
+		if matched[want.BalanceThreshold] {
+			continue
+		}
+		created, err := c.CreateLowBalanceAlert(accountIdentifier, want)
+		if err != nil {
+			return result, err
+		}
+		result.Created = append(result.Created, created)
+	}
+
+	return result, nil
+}
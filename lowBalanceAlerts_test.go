@@ -0,0 +1,139 @@
+package tango
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// alertServer is an in-memory stand-in for the low balance alert endpoints.
+type alertServer struct {
+	mu     sync.Mutex
+	alerts map[string]LowBalanceAlert
+	nextID int
+	calls  []string
+}
+
+func (s *alertServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	const prefix = "/accounts/acct/lowbalance"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+	s.calls = append(s.calls, r.Method+" "+id)
+
+	var body LowBalanceAlertRequest
+	if r.Method == http.MethodPost || r.Method == http.MethodPatch {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	switch {
+	case r.Method == http.MethodGet && id == "":
+		alerts := make([]LowBalanceAlert, 0, len(s.alerts))
+		for _, alert := range s.alerts {
+			alerts = append(alerts, alert)
+		}
+		sort.Slice(alerts, func(i, j int) bool { return alerts[i].BalanceAlertID < alerts[j].BalanceAlertID })
+		_ = json.NewEncoder(w).Encode(alerts)
+	case r.Method == http.MethodPost && id == "":
+		s.nextID++
+		alert := LowBalanceAlert{BalanceAlertID: fmt.Sprintf("A%d", s.nextID), BalanceThreshold: body.BalanceThreshold, EmailsToNotify: body.EmailsToNotify}
+		s.alerts[alert.BalanceAlertID] = alert
+		_ = json.NewEncoder(w).Encode(alert)
+	case r.Method == http.MethodPatch:
+		alert := s.alerts[id]
+		alert.BalanceThreshold, alert.EmailsToNotify = body.BalanceThreshold, body.EmailsToNotify
+		s.alerts[id] = alert
+		_ = json.NewEncoder(w).Encode(alert)
+	case r.Method == http.MethodDelete:
+		delete(s.alerts, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestLowBalanceAlerts(t *testing.T) {
+	server := &alertServer{alerts: map[string]LowBalanceAlert{}}
+	newTestServer(t, server.ServeHTTP)
+	client := &TangoClient{Token: "token"}
+
+	alert, err := client.CreateLowBalanceAlert("acct", LowBalanceAlertRequest{BalanceThreshold: 500, EmailsToNotify: []string{"ops@example.com"}})
+	if err != nil || alert.BalanceAlertID != "A1" {
+		t.Fatalf("unexpected alert: %+v, %v", alert, err)
+	}
+
+	alert, err = client.UpdateLowBalanceAlert("acct", "A1", LowBalanceAlertRequest{BalanceThreshold: 250, EmailsToNotify: []string{"ops@example.com"}})
+	if err != nil || alert.BalanceThreshold != 250 {
+		t.Fatalf("unexpected alert: %+v, %v", alert, err)
+	}
+
+	alerts, err := client.GetLowBalanceAlerts("acct")
+	if err != nil || len(alerts) != 1 || alerts[0].BalanceThreshold != 250 {
+		t.Fatalf("unexpected alerts: %+v, %v", alerts, err)
+	}
+
+	if err := client.DeleteLowBalanceAlert("acct", "A1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if alerts, _ := client.GetLowBalanceAlerts("acct"); len(alerts) != 0 {
+		t.Fatalf("expected no alerts, got %+v", alerts)
+	}
+
+	if _, err := client.CreateLowBalanceAlert("acct", LowBalanceAlertRequest{BalanceThreshold: 100}); err == nil {
+		t.Fatal("expected an error for an alert with no recipients")
+	}
+}
+
+func TestSyncLowBalanceAlerts(t *testing.T) {
+	server := &alertServer{alerts: map[string]LowBalanceAlert{
+		"A1": {BalanceAlertID: "A1", BalanceThreshold: 1000, EmailsToNotify: []string{"Finance@example.com", "ops@example.com"}},
+		"A2": {BalanceAlertID: "A2", BalanceThreshold: 500, EmailsToNotify: []string{"ops@example.com"}},
+		"A3": {BalanceAlertID: "A3", BalanceThreshold: 500, EmailsToNotify: []string{"ops@example.com"}},
+		"A4": {BalanceAlertID: "A4", BalanceThreshold: 50, EmailsToNotify: []string{"old@example.com"}},
+	}, nextID: 4}
+	newTestServer(t, server.ServeHTTP)
+	client := &TangoClient{Token: "token"}
+
+	desired := []LowBalanceAlertRequest{
+		{BalanceThreshold: 1000, EmailsToNotify: []string{"ops@example.com", "finance@example.com"}},
+		{BalanceThreshold: 500, EmailsToNotify: []string{"ops@example.com", "cfo@example.com"}},
+		{BalanceThreshold: 100, EmailsToNotify: []string{"cfo@example.com"}},
+	}
+	result, err := client.SyncLowBalanceAlerts("acct", desired)
+	if err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if len(result.Unchanged) != 1 || result.Unchanged[0].BalanceAlertID != "A1" {
+		t.Fatalf("expected A1 unchanged, got %+v", result.Unchanged)
+	}
+	if len(result.Updated) != 1 || result.Updated[0].BalanceAlertID != "A2" {
+		t.Fatalf("expected A2 updated, got %+v", result.Updated)
+	}
+	if len(result.Deleted) != 2 || result.Deleted[0].BalanceAlertID != "A3" || result.Deleted[1].BalanceAlertID != "A4" {
+		t.Fatalf("expected the duplicate and the stale alert deleted, got %+v", result.Deleted)
+	}
+	if len(result.Created) != 1 || result.Created[0].BalanceThreshold != 100 {
+		t.Fatalf("expected the 100 alert created, got %+v", result.Created)
+	}
+
+	// A second sync has nothing to do
+	server.calls = nil
+	result, err = client.SyncLowBalanceAlerts("acct", desired)
+	if err != nil || result.Changed() || len(server.calls) != 1 {
+		t.Fatalf("expected a no-op sync, got %+v, %v, calls %v", result, err, server.calls)
+	}
+
+	_, err = client.SyncLowBalanceAlerts("acct", append(desired, desired[0]))
+	if err == nil || !strings.Contains(err.Error(), "more than once") {
+		t.Fatalf("expected an error for duplicate thresholds, got %v", err)
+	}
+}
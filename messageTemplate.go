@@ -0,0 +1,157 @@
+package tango
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+)
+
+// Length limits Tango applies to the reward email fields, in characters.
+const (
+	MaxEmailSubjectLength = 100
+	MaxMessageLength      = 1000
+)
+
+// MessageData is what a MessageTemplate is rendered with. Templates refer to
+// it as {{.Recipient.FirstName}}, {{.Sender.LastName}}, {{.Vars.code}} and so on.
+type MessageData struct {
+	Recipient Person
+	Sender    Sender
+	Vars      map[string]string
+}
+
+/*
+MessageTemplate renders an order's EmailSubject and Message for each recipient
+from a campaign-wide template written in text/template syntax:
+
+	tmpl, err := tango.NewMessageTemplate(
+		"A thank you for {{.Recipient.FirstName}}",
+		"Hi {{.Recipient.FirstName}}, thanks for {{.Vars.reason}}!",
+	)
+
+The template text itself is trusted. The values substituted into the message
+are HTML-escaped, since Tango shows it in an HTML email; values substituted
+into the subject have line breaks replaced with spaces. Referring to a Vars key
+that was not supplied is an error rather than rendering "<no value>".
+*/
+type MessageTemplate struct {
+	subject *template.Template
+	message *template.Template
+}
+
+// NewMessageTemplate parses the subject and message templates. Either may be
+// empty, in which case Apply leaves that field of the order as it is.
+func NewMessageTemplate(subject, message string) (*MessageTemplate, error) {
+	t := &MessageTemplate{}
+	var err error
+	if subject != "" {
+		if t.subject, err = parseMessageTemplate("emailSubject", subject); err != nil {
+			return nil, err
+		}
+	}
+	if message != "" {
+		if t.message, err = parseMessageTemplate("message", message); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+func parseMessageTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// Render returns the subject and message for data. Rendered text longer than
+// Tango allows is reported as ValidationErrors.
+func (t *MessageTemplate) Render(data MessageData) (subject, message string, err error) {
+	var errs ValidationErrors
+
+	if t.subject != nil {
+		subject, err = execute(t.subject, mapMessageData(data, func(s string) string {
+			return strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(s)
+		}))
+		if err != nil {
+			return "", "", err
+		}
+		if n := utf8.RuneCountInString(subject); n > MaxEmailSubjectLength {
+			errs = append(errs, FieldError{Field: "emailSubject", Message: fmt.Sprintf("is %d characters, the limit is %d", n, MaxEmailSubjectLength)})
+		}
+	}
+
+	if t.message != nil {
+		message, err = execute(t.message, mapMessageData(data, html.EscapeString))
+		if err != nil {
+			return "", "", err
+		}
+		if n := utf8.RuneCountInString(message); n > MaxMessageLength {
+			errs = append(errs, FieldError{Field: "message", Message: fmt.Sprintf("is %d characters, the limit is %d", n, MaxMessageLength)})
+		}
+	}
+
+	if len(errs) > 0 {
+		return "", "", errs
+	}
+	return subject, message, nil
+}
+
+// Apply renders the template for the order's own recipient and sender plus
+// vars, and returns the order with EmailSubject and Message filled in.
+func (t *MessageTemplate) Apply(order CreateOrderData, vars map[string]string) (CreateOrderData, error) {
+	subject, message, err := t.Render(MessageData{Recipient: order.Recipient, Sender: order.Sender, Vars: vars})
+	if err != nil {
+		return order, err
+	}
+	if t.subject != nil {
+		order.EmailSubject = subject
+	}
+	if t.message != nil {
+		order.Message = message
+	}
+	return order, nil
+}
+
+func execute(tmpl *template.Template, data MessageData) (string, error) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering %s: %w", tmpl.Name(), err)
+	}
+	return buf.String(), nil
+}
+
+// mapMessageData returns a copy of data with f applied to every value.
+func mapMessageData(data MessageData, f func(string) string) MessageData {
+	r := data.Recipient
+	mapped := MessageData{
+		Recipient: Person{
+			FirstName:   f(r.FirstName),
+			LastName:    f(r.LastName),
+			Email:       f(r.Email),
+			PhoneNumber: f(r.PhoneNumber),
+			Address: Address{
+				StreetLine1:     f(r.Address.StreetLine1),
+				StreetLine2:     f(r.Address.StreetLine2),
+				City:            f(r.Address.City),
+				StateOrProvince: f(r.Address.StateOrProvince),
+				PostalCode:      f(r.Address.PostalCode),
+				Country:         f(r.Address.Country),
+			},
+		},
+		Sender: Sender{
+			FirstName: f(data.Sender.FirstName),
+			LastName:  f(data.Sender.LastName),
+			Email:     f(data.Sender.Email),
+		},
+		Vars: make(map[string]string, len(data.Vars)),
+	}
+	for key, value := range data.Vars {
+		mapped.Vars[key] = f(value)
+	}
+	return mapped
+}
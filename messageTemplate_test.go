@@ -0,0 +1,66 @@
+package tango
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMessageTemplate_Apply(t *testing.T) {
+	tmpl, err := NewMessageTemplate(
+		"Thanks, {{.Recipient.FirstName}}!",
+		"<p>Hi {{.Recipient.FirstName}}, {{.Sender.FirstName}} says thanks for {{.Vars.reason}}.</p>",
+	)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	order := CreateOrderData{
+		Recipient: Person{FirstName: "Ann\r\n<b>"},
+		Sender:    Sender{FirstName: "Bo & Co"},
+	}
+	got, err := tmpl.Apply(order, map[string]string{"reason": `<script>alert("x")</script>`})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+
+	if got.EmailSubject != "Thanks, Ann <b>!" {
+		t.Fatalf("unexpected subject %q", got.EmailSubject)
+	}
+	want := "<p>Hi Ann\r\n&lt;b&gt;, Bo &amp; Co says thanks for &lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt;.</p>"
+	if got.Message != want {
+		t.Fatalf("unexpected message:\n got %q\nwant %q", got.Message, want)
+	}
+	if order.Message != "" {
+		t.Fatal("expected the original order to be left alone")
+	}
+}
+
+func TestMessageTemplate_Errors(t *testing.T) {
+	if _, err := NewMessageTemplate("{{.Recipient.FirstName", ""); err == nil {
+		t.Fatal("expected a parse error")
+	}
+
+	tmpl, _ := NewMessageTemplate("", "Code: {{.Vars.code}}")
+	if _, err := tmpl.Apply(CreateOrderData{}, nil); err == nil || !strings.Contains(err.Error(), "code") {
+		t.Fatalf("expected an error for the missing variable, got %v", err)
+	}
+
+	// Escaping counts towards the limit
+	tmpl, _ = NewMessageTemplate("{{.Vars.subject}}", "{{.Vars.message}}")
+	_, err := tmpl.Apply(CreateOrderData{}, map[string]string{
+		"subject": strings.Repeat("x", MaxEmailSubjectLength+1),
+		"message": strings.Repeat("&", MaxMessageLength/4),
+	})
+	var fieldErrs ValidationErrors
+	if !errors.As(err, &fieldErrs) || len(fieldErrs) != 2 || fieldErrs[0].Field != "emailSubject" || fieldErrs[1].Field != "message" {
+		t.Fatalf("expected both fields to be too long, got %v", err)
+	}
+
+	// An empty template leaves the order's own value in place
+	tmpl, _ = NewMessageTemplate("", "Hello")
+	got, err := tmpl.Apply(CreateOrderData{EmailSubject: "Fixed"}, nil)
+	if err != nil || got.EmailSubject != "Fixed" || got.Message != "Hello" {
+		t.Fatalf("unexpected order %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,169 @@
+package tango
+
+import (
+	"errors"
+	"fmt"
+)
+
+/*
+OrderBuilder assembles CreateOrderData one piece at a time and checks it against
+the delivery method's requirements in Build.
+
+	order, err := tango.NewOrderBuilder("customer-id", "U123456").
+		Amount(25).
+		ToEmail("Ada", "Lovelace", "ada@example.com").
+		WithSender("Rewards", "Team", "rewards@example.com").
+		WithTemplate("E000000").
+		Build()
+*/
+type OrderBuilder struct {
+	data CreateOrderData
+	errs ValidationErrors
+}
+
+// NewOrderBuilder starts an order for the given customer and reward item.
+func NewOrderBuilder(customerIdentifier, utid string) *OrderBuilder {
+	return &OrderBuilder{
+		data: CreateOrderData{
+			CustomerIdentifier: customerIdentifier,
+			Utid:               utid,
+		},
+	}
+}
+
+// Amount sets the face value of the reward.
+func (b *OrderBuilder) Amount(amount float64) *OrderBuilder {
+	b.data.Amount = amount
+	return b
+}
+
+// ExternalRefID sets your own identifier for the order.
+func (b *OrderBuilder) ExternalRefID(externalRefID string) *OrderBuilder {
+	b.data.ExternalRefID = externalRefID
+	return b
+}
+
+// Campaign sets the campaign name used in Tango reporting.
+func (b *OrderBuilder) Campaign(campaign string) *OrderBuilder {
+	b.data.Campaign = campaign
+	return b
+}
+
+// Notes sets internal notes on the order.
+func (b *OrderBuilder) Notes(notes string) *OrderBuilder {
+	b.data.Notes = notes
+	return b
+}
+
+// ToEmail delivers the reward by email.
+func (b *OrderBuilder) ToEmail(firstName, lastName, email string) *OrderBuilder {
+	b.setDelivery(DeliveryMethodEmail, Person{
+		FirstName: firstName,
+		LastName:  lastName,
+		Email:     email,
+	})
+	return b
+}
+
+// ToAddress delivers a physical reward to a postal address.
+func (b *OrderBuilder) ToAddress(firstName, lastName string, address Address) *OrderBuilder {
+	b.setDelivery(DeliveryMethodAddress, Person{
+		FirstName: firstName,
+		LastName:  lastName,
+		Address:   address,
+	})
+	return b
+}
+
+// ToPhone delivers the reward by text message. The phone number must be in
+// international format and is normalized to E.164; Build reports numbers that
+// cannot be parsed.
+func (b *OrderBuilder) ToPhone(firstName, lastName, phoneNumber string) *OrderBuilder {
+	if phone, err := ParsePhoneNumber(phoneNumber, ""); err == nil {
+		phoneNumber = phone.E164()
+	}
+	b.setDelivery(DeliveryMethodPhone, Person{
+		FirstName:   firstName,
+		LastName:    lastName,
+		PhoneNumber: phoneNumber,
+	})
+	return b
+}
+
+// Embedded returns the reward credentials in the order response instead of
+// delivering them to the recipient.
+func (b *OrderBuilder) Embedded() *OrderBuilder {
+	b.setDelivery(DeliveryMethodEmbedded, Person{})
+	return b
+}
+
+// WithSender sets who the reward is from.
+func (b *OrderBuilder) WithSender(firstName, lastName, email string) *OrderBuilder {
+	b.data.Sender = Sender{
+		FirstName: firstName,
+		LastName:  lastName,
+		Email:     email,
+	}
+	return b
+}
+
+// WithTemplate sets the email template (etid) used for EMAIL delivery.
+func (b *OrderBuilder) WithTemplate(etid string) *OrderBuilder {
+	b.data.Etid = etid
+	return b
+}
+
+// WithMessage sets the email subject and message used for EMAIL delivery.
+func (b *OrderBuilder) WithMessage(emailSubject, message string) *OrderBuilder {
+	b.data.EmailSubject = emailSubject
+	b.data.Message = message
+	return b
+}
+
+/*
+Build returns the order data, or ValidationErrors describing every missing or
+invalid field for the chosen delivery method.
+*/
+func (b *OrderBuilder) Build() (CreateOrderData, error) {
+	errs := append(ValidationErrors{}, b.errs...)
+
+	if b.data.DeliveryMethod == "" {
+		errs = append(errs, FieldError{
+			Field:   "deliveryMethod",
+			Message: "is required: call ToEmail, ToAddress, ToPhone or Embedded",
+		})
+	}
+	if b.data.DeliveryMethod != DeliveryMethodEmail {
+		if b.data.Etid != "" {
+			errs = append(errs, FieldError{Field: "etid", Message: "only applies to EMAIL delivery"})
+		}
+		if b.data.EmailSubject != "" || b.data.Message != "" {
+			errs = append(errs, FieldError{Field: "emailSubject", Message: "only applies to EMAIL delivery"})
+		}
+	}
+
+	if err := b.data.Validate(); err != nil {
+		var validationErrs ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			return CreateOrderData{}, err
+		}
+		errs = append(errs, validationErrs...)
+	}
+
+	if len(errs) > 0 {
+		return CreateOrderData{}, errs
+	}
+	return b.data, nil
+}
+
+func (b *OrderBuilder) setDelivery(method string, recipient Person) {
+	if b.data.DeliveryMethod != "" && b.data.DeliveryMethod != method {
+		b.errs = append(b.errs, FieldError{
+			Field:   "deliveryMethod",
+			Message: fmt.Sprintf("cannot deliver by %s: delivery method is already %s", method, b.data.DeliveryMethod),
+		})
+		return
+	}
+	b.data.DeliveryMethod = method
+	b.data.Recipient = recipient
+}
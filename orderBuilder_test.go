@@ -0,0 +1,84 @@
+package tango
+
+import (
+	"errors"
+	"testing"
+)
+
+func fieldsOf(t *testing.T, err error) map[string]bool {
+	t.Helper()
+
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	fields := map[string]bool{}
+	for _, fieldErr := range errs {
+		fields[fieldErr.Field] = true
+	}
+	return fields
+}
+
+func TestOrderBuilder_Email(t *testing.T) {
+	order, err := NewOrderBuilder("cust", "U1").
+		Amount(10).
+		ExternalRefID("ref-1").
+		ToEmail("Ada", "Lovelace", "ada@example.com").
+		WithSender("Rewards", "Team", "rewards@example.com").
+		WithTemplate("E1").
+		WithMessage("Thanks", "For everything").
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if order.DeliveryMethod != DeliveryMethodEmail || order.Recipient.Email != "ada@example.com" || order.Etid != "E1" {
+		t.Fatalf("unexpected order: %+v", order)
+	}
+
+	_, err = NewOrderBuilder("cust", "U1").Amount(10).ToEmail("", "", "nope").Build()
+	fields := fieldsOf(t, err)
+	if !fields["recipient.firstName"] || !fields["recipient.email"] {
+		t.Fatalf("expected recipient errors, got %v", err)
+	}
+}
+
+func TestOrderBuilder_Address(t *testing.T) {
+	_, err := NewOrderBuilder("cust", "U1").
+		Amount(10).
+		ToAddress("Ada", "Lovelace", Address{City: "London", Country: "GB"}).
+		Build()
+	fields := fieldsOf(t, err)
+	for _, field := range []string{"recipient.address.streetLine1", "recipient.address.stateOrProvince", "recipient.address.postalCode"} {
+		if !fields[field] {
+			t.Fatalf("expected error for %s, got %v", field, err)
+		}
+	}
+	if fields["recipient.address.city"] {
+		t.Fatalf("unexpected city error: %v", err)
+	}
+}
+
+func TestOrderBuilder_Rules(t *testing.T) {
+	_, err := NewOrderBuilder("", "").Build()
+	fields := fieldsOf(t, err)
+	for _, field := range []string{"customerIdentifier", "utid", "amount", "deliveryMethod"} {
+		if !fields[field] {
+			t.Fatalf("expected error for %s, got %v", field, err)
+		}
+	}
+
+	_, err = NewOrderBuilder("cust", "U1").Amount(5).Embedded().WithTemplate("E1").Build()
+	if !fieldsOf(t, err)["etid"] {
+		t.Fatalf("expected etid error for embedded delivery, got %v", err)
+	}
+
+	_, err = NewOrderBuilder("cust", "U1").Amount(5).Embedded().ToEmail("Ada", "", "ada@example.com").Build()
+	if !fieldsOf(t, err)["deliveryMethod"] {
+		t.Fatalf("expected conflicting delivery method error, got %v", err)
+	}
+
+	order, err := NewOrderBuilder("cust", "U1").Amount(5).Embedded().Build()
+	if err != nil || order.DeliveryMethod != DeliveryMethodEmbedded {
+		t.Fatalf("expected embedded order, got %+v, %v", order, err)
+	}
+}
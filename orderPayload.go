@@ -0,0 +1,128 @@
+package tango
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+/*
+orderRequest is the body Order sends to POST /orders. marshalOrderRequest
+prunes empty strings and objects, so an empty string in CreateOrderData means
+"not set". Numbers and booleans are not pruned: amount is always sent, even
+when it is 0, and sendEmail is a pointer only so that it can be left out when
+DeliveryMethod is used instead.
+*/
+type orderRequest struct {
+	ExternalRefID      string  `json:"externalRefID"`
+	CustomerIdentifier string  `json:"customerIdentifier"`
+	AccountIdentifier  string  `json:"accountIdentifier"`
+	Utid               string  `json:"utid"`
+	Amount             float64 `json:"amount"`
+	EmailSubject       string  `json:"emailSubject"`
+	Message            string  `json:"message"`
+	SendEmail          *bool   `json:"sendEmail,omitempty"` // Deprecated: use deliveryMethod instead
+	DeliveryMethod     string  `json:"deliveryMethod"`
+	Etid               string  `json:"etid"`
+	Campaign           string  `json:"campaign"`
+	Notes              string  `json:"notes"`
+	Sender             Sender  `json:"sender"`
+	Recipient          Person  `json:"recipient"`
+}
+
+// newOrderRequest maps order data onto the request body, applying the client's
+// account and SendEmail settings and normalizing the recipient phone number.
+func (c *TangoClient) newOrderRequest(data CreateOrderData) (orderRequest, error) {
+	recipient := data.Recipient
+
+	payload := orderRequest{
+		AccountIdentifier:  c.AccountIdentifier,
+		ExternalRefID:      data.ExternalRefID,
+		CustomerIdentifier: data.CustomerIdentifier,
+		Utid:               data.Utid,
+		Amount:             data.Amount,
+		EmailSubject:       data.EmailSubject,
+		Message:            data.Message,
+		Etid:               data.Etid,
+		Campaign:           data.Campaign,
+		Notes:              data.Notes,
+		Sender:             data.Sender,
+	}
+
+	// Use DeliveryMethod if provided, otherwise fall back to SendEmail for backward compatibility.
+	// sendEmail is deprecated, so it is only sent when deliveryMethod is not.
+	if data.DeliveryMethod != "" {
+		payload.DeliveryMethod = data.DeliveryMethod
+	} else {
+		sendEmail := c.SendEmail
+		payload.SendEmail = &sendEmail
+	}
+
+	if recipient.PhoneNumber != "" {
+		phone, err := ParsePhoneNumber(recipient.PhoneNumber, recipientPhoneRegion(recipient))
+		if err != nil {
+			return orderRequest{}, fmt.Errorf("invalid recipient phone number: %w", err)
+		}
+		recipient.PhoneNumber = phone.E164()
+	}
+
+	// Tango rejects partial addresses, so an address without a street is dropped
+	if recipient.Address.StreetLine1 == "" {
+		recipient.Address = Address{}
+	}
+	payload.Recipient = recipient
+
+	return payload, nil
+}
+
+/*
+marshalOrderRequest encodes the request body with every empty string, null and
+empty object removed at any depth, so an unset sender or address is left out
+entirely instead of being sent as a set of blank fields. false and 0 are kept.
+*/
+func marshalOrderRequest(payload orderRequest) ([]byte, error) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var payloadMap map[string]interface{}
+	if err := json.Unmarshal(payloadBytes, &payloadMap); err != nil {
+		return nil, err
+	}
+
+	pruned, _ := pruneEmpty(payloadMap)
+	if pruned == nil {
+		pruned = map[string]interface{}{}
+	}
+	return json.Marshal(pruned)
+}
+
+// pruneEmpty returns value with empty members removed, and whether anything is left.
+func pruneEmpty(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return v, v != ""
+	case map[string]interface{}:
+		for key, member := range v {
+			pruned, keep := pruneEmpty(member)
+			if !keep {
+				delete(v, key)
+				continue
+			}
+			v[key] = pruned
+		}
+		return v, len(v) > 0
+	case []interface{}:
+		kept := v[:0]
+		for _, element := range v {
+			if pruned, keep := pruneEmpty(element); keep {
+				kept = append(kept, pruned)
+			}
+		}
+		return kept, len(kept) > 0
+	default:
+		return v, true
+	}
+}
@@ -0,0 +1,146 @@
+package tango
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+// TestMarshalOrderRequest_Golden pins the body sent to POST /orders for each
+// delivery method. The golden files are indented for review; run with -update
+// after an intentional change.
+func TestMarshalOrderRequest_Golden(t *testing.T) {
+	sender := Sender{FirstName: "Rewards", LastName: "Team", Email: "rewards@example.com"}
+	address := Address{
+		StreetLine1:     "1 Main St",
+		City:            "Springfield",
+		StateOrProvince: "IL",
+		PostalCode:      "62701",
+		Country:         "US",
+	}
+
+	tests := []struct {
+		name      string
+		sendEmail bool
+		data      CreateOrderData
+	}{
+		{
+			name: "email",
+			data: CreateOrderData{
+				ExternalRefID:      "ref-email",
+				CustomerIdentifier: "cust",
+				Utid:               "U1",
+				Amount:             25,
+				EmailSubject:       "Thank you",
+				Message:            "Enjoy!",
+				Etid:               "E1",
+				DeliveryMethod:     DeliveryMethodEmail,
+				Sender:             sender,
+				Recipient:          Person{FirstName: "Ada", LastName: "Lovelace", Email: "ada@example.com"},
+			},
+		},
+		{
+			name: "address",
+			data: CreateOrderData{
+				CustomerIdentifier: "cust",
+				Utid:               "U1",
+				Amount:             50,
+				DeliveryMethod:     DeliveryMethodAddress,
+				Recipient:          Person{FirstName: "Ada", LastName: "Lovelace", Address: address},
+			},
+		},
+		{
+			name: "phone",
+			data: CreateOrderData{
+				CustomerIdentifier: "cust",
+				Utid:               "U1",
+				Amount:             10,
+				DeliveryMethod:     DeliveryMethodPhone,
+				Recipient:          Person{FirstName: "Ada", PhoneNumber: "(212) 555-0100", Address: Address{Country: "US"}},
+			},
+		},
+		{
+			name: "embedded",
+			data: CreateOrderData{
+				ExternalRefID:      "ref-embedded",
+				CustomerIdentifier: "cust",
+				Utid:               "U1",
+				Amount:             5.5,
+				DeliveryMethod:     DeliveryMethodEmbedded,
+				Sender:             Sender{},
+				Recipient:          Person{Address: Address{City: "Springfield"}},
+			},
+		},
+		{
+			name: "none",
+			data: CreateOrderData{
+				CustomerIdentifier: "cust",
+				Utid:               "U1",
+				Amount:             0,
+				DeliveryMethod:     DeliveryMethodNone,
+				Campaign:           "spring",
+			},
+		},
+		{
+			name:      "legacy_send_email_false",
+			sendEmail: false,
+			data: CreateOrderData{
+				CustomerIdentifier: "cust",
+				Utid:               "U1",
+				Amount:             5,
+				Recipient:          Person{Email: "ada@example.com"},
+			},
+		},
+		{
+			name:      "legacy_send_email_true",
+			sendEmail: true,
+			data: CreateOrderData{
+				CustomerIdentifier: "cust",
+				Utid:               "U1",
+				Amount:             5,
+				Recipient:          Person{Email: "ada@example.com"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &TangoClient{AccountIdentifier: "acct", SendEmail: tt.sendEmail}
+
+			payload, err := client.newOrderRequest(tt.data)
+			if err != nil {
+				t.Fatalf("newOrderRequest failed: %v", err)
+			}
+			got, err := marshalOrderRequest(payload)
+			if err != nil {
+				t.Fatalf("marshalOrderRequest failed: %v", err)
+			}
+
+			var indented bytes.Buffer
+			if err := json.Indent(&indented, got, "", "  "); err != nil {
+				t.Fatalf("indent failed: %v", err)
+			}
+			indented.WriteByte('\n')
+
+			path := filepath.Join("testdata", "orders", tt.name+".golden.json")
+			if *updateGolden {
+				if err := os.WriteFile(path, indented.Bytes(), 0o644); err != nil {
+					t.Fatalf("write golden file: %v", err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("read golden file (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(indented.Bytes(), want) {
+				t.Fatalf("payload mismatch for %s\ngot:\n%s\nwant:\n%s", tt.name, indented.String(), want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package tango
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/c150pilot/go-tango-card/internal/emails"
+)
+
+// Delivery methods accepted by CreateOrderData.DeliveryMethod.
+const (
+	DeliveryMethodNone     = "NONE"
+	DeliveryMethodEmail    = "EMAIL"
+	DeliveryMethodPhone    = "PHONE"
+	DeliveryMethodAddress  = "ADDRESS"
+	DeliveryMethodEmbedded = "EMBEDDED"
+)
+
+// FieldError describes a single invalid field in an order. Field is the JSON
+// path of the field, e.g. "recipient.address.city".
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors lists every problem found with an order.
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Error())
+	}
+	return "invalid order: " + strings.Join(messages, "; ")
+}
+
+/*
+Validate checks the order against the rules Tango applies to each delivery method:
+
+  - EMAIL requires the recipient's first name and a valid email address
+  - ADDRESS requires the recipient's full name and street, city, state or
+    province, postal code and country. Order drops the address entirely when
+    StreetLine1 is empty, so it is always required here.
+  - PHONE requires a recipient phone number. Numbers in national format are
+    read using the recipient's address country, otherwise they need a
+    +country code
+  - EMBEDDED and NONE need no recipient details
+
+An empty DeliveryMethod uses the legacy SendEmail behaviour and only the common
+fields are checked. EmailSubject and Message must fit within
+MaxEmailSubjectLength and MaxMessageLength for every method. Validate returns nil or ValidationErrors.
+*/
+func (d CreateOrderData) Validate() error {
+	var errs ValidationErrors
+	fail := func(field, format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if d.CustomerIdentifier == "" {
+		fail("customerIdentifier", "is required")
+	}
+	if d.Utid == "" {
+		fail("utid", "is required")
+	}
+	if d.Amount <= 0 {
+		fail("amount", "must be greater than zero")
+	}
+	if d.Recipient.Email != "" && !emails.Valid(d.Recipient.Email) {
+		fail("recipient.email", "invalid email address %q", d.Recipient.Email)
+	}
+	if d.Recipient.PhoneNumber != "" {
+		if _, err := ParsePhoneNumber(d.Recipient.PhoneNumber, recipientPhoneRegion(d.Recipient)); err != nil {
+			fail("recipient.phoneNumber", "%v", err)
+		}
+	}
+	if d.Sender.Email != "" && !emails.Valid(d.Sender.Email) {
+		fail("sender.email", "invalid email address %q", d.Sender.Email)
+	}
+	if n := utf8.RuneCountInString(d.EmailSubject); n > MaxEmailSubjectLength {
+		fail("emailSubject", "is %d characters, the limit is %d", n, MaxEmailSubjectLength)
+	}
+	if n := utf8.RuneCountInString(d.Message); n > MaxMessageLength {
+		fail("message", "is %d characters, the limit is %d", n, MaxMessageLength)
+	}
+
+	recipient := d.Recipient
+	switch d.DeliveryMethod {
+	case "", DeliveryMethodNone, DeliveryMethodEmbedded:
+	case DeliveryMethodEmail:
+		if recipient.FirstName == "" {
+			fail("recipient.firstName", "is required for EMAIL delivery")
+		}
+		if recipient.Email == "" {
+			fail("recipient.email", "is required for EMAIL delivery")
+		}
+	case DeliveryMethodAddress:
+		required := []struct{ field, value string }{
+			{"recipient.firstName", recipient.FirstName},
+			{"recipient.lastName", recipient.LastName},
+			{"recipient.address.streetLine1", recipient.Address.StreetLine1},
+			{"recipient.address.city", recipient.Address.City},
+			{"recipient.address.stateOrProvince", recipient.Address.StateOrProvince},
+			{"recipient.address.postalCode", recipient.Address.PostalCode},
+			{"recipient.address.country", recipient.Address.Country},
+		}
+		for _, r := range required {
+			if strings.TrimSpace(r.value) == "" {
+				fail(r.field, "is required for ADDRESS delivery")
+			}
+		}
+	case DeliveryMethodPhone:
+		if recipient.PhoneNumber == "" {
+			fail("recipient.phoneNumber", "is required for PHONE delivery")
+		}
+	default:
+		fail("deliveryMethod", "unknown delivery method %q", d.DeliveryMethod)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
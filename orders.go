@@ -1,11 +1,11 @@
 package tango
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
-
-	"github.com/go-resty/resty/v2"
 )
 
 type CreateOrderData struct {
@@ -23,6 +23,8 @@ type CreateOrderData struct {
 	Recipient          Person
 }
 
+// CreateOrderRequest mirrors the body of POST /orders. Order builds its own
+// body from CreateOrderData; this type is kept for callers that use it directly.
 type CreateOrderRequest struct {
 	ExternalRefID      string  `json:"externalRefID"`
 	CustomerIdentifier string  `json:"customerIdentifier"`
@@ -56,18 +58,12 @@ type Address struct {
 }
 
 type CreateOrderResponseError struct {
-	Timestamp  time.Time `json:"timestamp"`
-	RequestId  string    `json:"requestId"`
-	Path       string    `json:"path"`
-	HttpCode   int       `json:"httpCode"`
-	HttpPhrase string    `json:"httpPhrase"`
-	Errors     []struct {
-		Path         string `json:"path"`
-		I18NKey      string `json:"i18nKey,omitempty"`
-		Message      string `json:"message"`
-		InvalidValue string `json:"invalidValue"`
-		Constraint   string `json:"constraint"`
-	} `json:"errors"`
+	Timestamp  time.Time        `json:"timestamp"`
+	RequestId  string           `json:"requestId"`
+	Path       string           `json:"path"`
+	HttpCode   int              `json:"httpCode"`
+	HttpPhrase string           `json:"httpPhrase"`
+	Errors     []APIErrorDetail `json:"errors"`
 }
 
 type CreateOrderResponse struct {
@@ -113,106 +109,106 @@ type CredentialList struct {
 }
 
 type Person struct {
-	FirstName string  `json:"firstName"`
-	LastName  string  `json:"lastName"`
-	Email     string  `json:"email"`
-	Address   Address `json:"address"`
+	FirstName   string  `json:"firstName"`
+	LastName    string  `json:"lastName"`
+	Email       string  `json:"email"`
+	PhoneNumber string  `json:"phoneNumber,omitempty"` // E.164, required for PHONE delivery
+	Address     Address `json:"address"`
 }
 
-func (c *TangoClient) Order(data CreateOrderData) (CreateOrderResponse, error) {
-	url := ApiURL + "/orders"
-
-	// Transfer data to payload
-	payload := CreateOrderRequest{
-		AccountIdentifier:  c.AccountIdentifier,
-		ExternalRefID:      data.ExternalRefID,
-		CustomerIdentifier: data.CustomerIdentifier,
-		Utid:               data.Utid,
-		Amount:             data.Amount,
-		EmailSubject:       data.EmailSubject,
-		Message:            data.Message,
-		Etid:               data.Etid,
-		Campaign:           data.Campaign,
-		Notes:              data.Notes,
-		Sender:             data.Sender,
-		Recipient:          data.Recipient,
-	}
+/*
+Order places an order for a reward.
+https://developers.tangocard.com/reference/createorder
 
-	// Use DeliveryMethod if provided, otherwise fall back to SendEmail for backward compatibility
-	// Note: When deliveryMethod is set, we should NOT include sendEmail as it's deprecated
-	if data.DeliveryMethod != "" {
-		payload.DeliveryMethod = data.DeliveryMethod
-		// Don't set SendEmail when using deliveryMethod
-	} else {
-		// Legacy behavior: use SendEmail from client config
-		payload.SendEmail = c.SendEmail
-	}
-
-	// Marshal payload to JSON
-	payloadBytes, err := json.Marshal(payload)
-	if err != nil {
+When the client has a Journal, the attempt is recorded before the request is
+sent and its outcome after the response arrives; see Journal and Recover.
+With CheckEmailTemplates set, the order's Etid is looked up first.
+*/
+func (c *TangoClient) Order(data CreateOrderData) (CreateOrderResponse, error) {
+	if err := c.checkOrderEmailTemplate(data); err != nil {
 		return CreateOrderResponse{}, err
 	}
 
-	payloadMap, err := clean(payloadBytes)
+	payload, err := c.newOrderRequest(data)
 	if err != nil {
 		return CreateOrderResponse{}, err
 	}
 
-	// If recipient[address] is empty, remove it from the payload
-	if payloadMap["recipient"] != nil {
-		recipient, ok := payloadMap["recipient"].(map[string]interface{})
-		if !ok {
-			return CreateOrderResponse{}, fmt.Errorf("recipient is not a map[string]interface{}")
-		}
-		if recipient["address"] != nil {
-			address, ok := recipient["address"].(map[string]interface{})
-			if !ok {
-				return CreateOrderResponse{}, fmt.Errorf("address is not a map[string]interface{}")
+	// Keep the recipient phone number out of any errors
+	redact := func(err error) error { return err }
+	if payload.Recipient.PhoneNumber != "" {
+		phoneNumber := payload.Recipient.PhoneNumber
+		redact = func(err error) error {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				return errors.New(redactPhoneNumberIn(err.Error(), phoneNumber))
 			}
-			streetLine1, ok := address["streetLine1"].(string)
-			if ok && streetLine1 == "" {
-				delete(recipient, "address")
+			redacted := *apiErr
+			redacted.message = redactPhoneNumberIn(apiErr.Error(), phoneNumber)
+			redacted.Body = redactPhoneNumberIn(apiErr.Body, phoneNumber)
+			redacted.Errors = make([]APIErrorDetail, len(apiErr.Errors))
+			for i, detail := range apiErr.Errors {
+				detail.InvalidValue = redactPhoneNumberIn(detail.InvalidValue, phoneNumber)
+				redacted.Errors[i] = detail
 			}
+			return &redacted
 		}
 	}
 
-	payloadJSON, err := json.Marshal(payloadMap)
+	payloadJSON, err := marshalOrderRequest(payload)
 	if err != nil {
 		return CreateOrderResponse{}, err
 	}
 
+	if c.Journal == nil {
+		return c.postOrder(payloadJSON, redact)
+	}
+
+	entry := JournalEntry{
+		ExternalRefID: data.ExternalRefID,
+		PayloadHash:   hashPayload(payloadJSON),
+		Outcome:       JournalPending,
+		Time:          timeNow(),
+	}
+	if err := c.Journal.Append(entry); err != nil {
+		return CreateOrderResponse{}, fmt.Errorf("order not sent: journal write failed: %w", err)
+	}
+
+	responseData, err := c.postOrder(payloadJSON, redact)
+
+	// A failed write here leaves the entry pending, which Recover resolves, so
+	// the order's own result is what gets returned.
+	_ = c.Journal.Append(completedJournalEntry(entry, responseData, err))
+
+	return responseData, err
+}
+
+// postOrder sends an order payload built by marshalOrderRequest.
+func (c *TangoClient) postOrder(payloadJSON []byte, redact func(error) error) (CreateOrderResponse, error) {
+	url := c.apiURL() + "/orders"
+
 	// Create HTTP Post Request with payload
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	// POST JSON string
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		SetBody(payloadJSON).
 		Post(url)
 	if err != nil {
 		return CreateOrderResponse{}, fmt.Errorf("HTTP request failed: %w", err)
 	}
 
-	// If status is not 2xx, check for errors
+	// If status is not 2xx, report the structured errors if there are any, otherwise the raw body
 	if statusErr := ensureSuccessStatus(resp, "create order"); statusErr != nil {
-		// Try to parse as error response
-		var responseError CreateOrderResponseError
-		unmarshalErr := json.Unmarshal(resp.Body(), &responseError)
-		if unmarshalErr == nil && len(responseError.Errors) > 0 {
-			return CreateOrderResponse{}, fmt.Errorf("Tango API error (status %d): %v", resp.StatusCode(), responseError.Errors)
-		}
-
-		// If not parseable as structured error, return raw body
-		return CreateOrderResponse{}, statusErr
+		return CreateOrderResponse{}, redact(withErrorDetails(statusErr))
 	}
 
 	// Check JSON response for errors (even on 2xx status)
 	var responseError CreateOrderResponseError
 	err = json.Unmarshal(resp.Body(), &responseError)
 	if err == nil && len(responseError.Errors) > 0 {
-		return CreateOrderResponse{}, fmt.Errorf("Tango API error: %v", responseError.Errors)
+		return CreateOrderResponse{}, redact(fmt.Errorf("Tango API error: %v", responseError.Errors))
 	}
 
 	if err != nil {
@@ -232,17 +228,21 @@ func (c *TangoClient) Order(data CreateOrderData) (CreateOrderResponse, error) {
 // GetOrder retrieves order details including credentials from Tango API
 // https://developers.tangocard.com/reference/get-details-for-a-specific-order
 func (c *TangoClient) GetOrder(referenceOrderID string) (CreateOrderResponse, error) {
+	return c.getOrder(context.Background(), referenceOrderID)
+}
+
+func (c *TangoClient) getOrder(ctx context.Context, referenceOrderID string) (CreateOrderResponse, error) {
 	if referenceOrderID == "" {
 		return CreateOrderResponse{}, fmt.Errorf("referenceOrderID is required")
 	}
 
-	url := fmt.Sprintf("%s/orders/%s", ApiURL, referenceOrderID)
+	url := fmt.Sprintf("%s/orders/%s", c.apiURL(), referenceOrderID)
 
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	resp, err := client.R().
+		SetContext(ctx).
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		Get(url)
 
 	if err != nil {
@@ -250,13 +250,7 @@ func (c *TangoClient) GetOrder(referenceOrderID string) (CreateOrderResponse, er
 	}
 
 	if statusErr := ensureSuccessStatus(resp, "get order"); statusErr != nil {
-		// Try to parse as error response
-		var responseError CreateOrderResponseError
-		unmarshalErr := json.Unmarshal(resp.Body(), &responseError)
-		if unmarshalErr == nil && len(responseError.Errors) > 0 {
-			return CreateOrderResponse{}, fmt.Errorf("Tango API error (status %d): %v", resp.StatusCode(), responseError.Errors)
-		}
-		return CreateOrderResponse{}, statusErr
+		return CreateOrderResponse{}, withErrorDetails(statusErr)
 	}
 
 	// Check JSON response for errors (even on 2xx status)
@@ -276,51 +270,88 @@ func (c *TangoClient) GetOrder(referenceOrderID string) (CreateOrderResponse, er
 	return responseData, nil
 }
 
+// OrdersResponse is a page of orders returned by the list orders endpoint.
+type OrdersResponse struct {
+	Page   OrdersPage            `json:"page"`
+	Orders []CreateOrderResponse `json:"orders"`
+}
+
+type OrdersPage struct {
+	Number           int `json:"number"`
+	ElementsPerBlock int `json:"elementsPerBlock"`
+	ResultCount      int `json:"resultCount"`
+	TotalCount       int `json:"totalCount"`
+}
+
+/*
+FindOrdersByExternalRefID lists the orders placed with the given externalRefID.
+https://developers.tangocard.com/reference/listorders
+*/
+func (c *TangoClient) FindOrdersByExternalRefID(externalRefID string) ([]CreateOrderResponse, error) {
+	if externalRefID == "" {
+		return nil, fmt.Errorf("externalRefID is required")
+	}
+
+	url := c.apiURL() + "/orders"
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetQueryParam("externalRefID", externalRefID).
+		Get(url)
+
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if err := ensureSuccessStatus(resp, "list orders"); err != nil {
+		return nil, err
+	}
+
+	var responseData OrdersResponse
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return nil, err
+	}
+
+	return responseData.Orders, nil
+}
+
 // ResendOrder resends an order email using Tango's resend API
 func (c *TangoClient) ResendOrder(referenceOrderID string) error {
 	if referenceOrderID == "" {
 		return fmt.Errorf("referenceOrderID is required")
 	}
 
-	url := fmt.Sprintf("%s/orders/%s/resends", ApiURL, referenceOrderID)
+	url := fmt.Sprintf("%s/orders/%s/resends", c.apiURL(), referenceOrderID)
 
 	// Create HTTP Post Request
-	client := resty.New()
+	client := c.newHTTPClient()
 
 	// POST request to resend order
 	resp, err := client.R().
 		SetHeader("Content-Type", "application/json").
-		SetHeader("Authorization", "Bearer "+c.Token).
 		Post(url)
 
 	if err != nil {
 		return fmt.Errorf("failed to resend order: %w", err)
 	}
 
-	if resp.StatusCode() != 200 && resp.StatusCode() != 201 && resp.StatusCode() != 204 {
-		return fmt.Errorf("resend order failed with status %d (%s): %s", resp.StatusCode(), resp.Status(), string(resp.Body()))
+	if err := ensureSuccessStatus(resp, "resend order"); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func clean(payload []byte) (map[string]interface{}, error) {
-	var dataMap map[string]interface{}
-	if err := json.Unmarshal(payload, &dataMap); err != nil {
-		return nil, err
-	}
-
-	// Remove empty and nil values from nested maps
-	// But preserve deliveryMethod even if empty string (it's a valid value)
-	for k, v := range dataMap {
-		if k == "deliveryMethod" {
-			// Keep deliveryMethod field even if empty - it's a valid API field
-			continue
-		}
-		if v == nil || v == "" || v == 0 {
-			delete(dataMap, k)
-		}
+// withErrorDetails words an *APIError that carries structured error details as
+// "Tango API error (status N): [...]".
+func withErrorDetails(err error) error {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || len(apiErr.Errors) == 0 {
+		return err
 	}
-
-	return dataMap, nil
+	detailed := *apiErr
+	detailed.message = fmt.Sprintf("Tango API error (status %d): %v", apiErr.StatusCode, apiErr.Errors)
+	return &detailed
 }
@@ -0,0 +1,251 @@
+package outbox
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	tango "github.com/c150pilot/go-tango-card"
+	"github.com/c150pilot/go-tango-card/internal/ids"
+	"github.com/c150pilot/go-tango-card/internal/jsonfile"
+)
+
+// DefaultLease is how long a claimed item is reserved for its worker.
+const DefaultLease = 5 * time.Minute
+
+/*
+FileStore is a Store kept in a single JSON file. Every change rewrites the
+file through a temporary file and a rename, so a crash leaves either the old
+or the new contents behind. It suits a single process; use a database-backed
+Store to share an outbox between several.
+*/
+type FileStore struct {
+	// Lease is how long a claim lasts. Zero uses DefaultLease.
+	Lease time.Duration
+
+	// Now is the clock used for Enqueue, Complete and Fail. Nil uses time.Now.
+	Now func() time.Time
+
+	mu    sync.Mutex
+	path  string
+	items map[string]Item
+}
+
+// OpenFileStore opens the outbox stored at path, creating it if needed.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, items: make(map[string]Item)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var items []Item
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("outbox file %s is corrupt: %w", path, err)
+	}
+	for _, item := range items {
+		s.items[item.ID] = item
+	}
+	return s, nil
+}
+
+func (s *FileStore) Enqueue(order tango.CreateOrderData) (Item, error) {
+	item, err := NewItem(order, s.now())
+	if err != nil {
+		return Item{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items[item.ID] = item
+	if err := s.save(); err != nil {
+		delete(s.items, item.ID)
+		return Item{}, err
+	}
+	return item, nil
+}
+
+func (s *FileStore) Claim(now time.Time, limit int) ([]Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lease := s.Lease
+	if lease <= 0 {
+		lease = DefaultLease
+	}
+
+	var claimed []Item
+	for _, item := range s.sorted() {
+		if limit > 0 && len(claimed) == limit {
+			break
+		}
+		due := item.State == StatePending && !item.NextAttempt.After(now)
+		expired := item.State == StateClaimed && !item.LeaseUntil.After(now)
+		if !due && !expired {
+			continue
+		}
+		claimID, err := ids.New()
+		if err != nil {
+			return nil, err
+		}
+		item.State = StateClaimed
+		item.ClaimID = claimID
+		item.Attempts++
+		item.LeaseUntil = now.Add(lease)
+		item.UpdatedAt = now
+		claimed = append(claimed, item)
+	}
+	if len(claimed) == 0 {
+		return nil, nil
+	}
+
+	previous := make([]Item, len(claimed))
+	for i, item := range claimed {
+		previous[i] = s.items[item.ID]
+		s.items[item.ID] = item
+	}
+	if err := s.save(); err != nil {
+		for _, item := range previous {
+			s.items[item.ID] = item
+		}
+		return nil, err
+	}
+	return claimed, nil
+}
+
+func (s *FileStore) Complete(claimed Item, referenceOrderID string) error {
+	return s.updateClaimed(claimed, func(item *Item) {
+		item.State = StateCompleted
+		item.ReferenceOrderID = referenceOrderID
+		item.LastError = ""
+	})
+}
+
+func (s *FileStore) Fail(claimed Item, reason string, retryAt time.Time) error {
+	return s.updateClaimed(claimed, func(item *Item) {
+		item.LastError = reason
+		if retryAt.IsZero() {
+			item.State = StateDead
+			return
+		}
+		item.State = StatePending
+		item.NextAttempt = retryAt
+	})
+}
+
+// updateClaimed applies change if claimed is still the item's current claim,
+// and releases the claim.
+func (s *FileStore) updateClaimed(claimed Item, change func(item *Item)) error {
+	return s.update(claimed.ID, func(item *Item) error {
+		if item.State != StateClaimed || item.ClaimID != claimed.ClaimID {
+			return ErrLeaseLost
+		}
+		change(item)
+		item.ClaimID = ""
+		item.LeaseUntil = time.Time{}
+		return nil
+	})
+}
+
+// Get returns a single item.
+func (s *FileStore) Get(id string) (Item, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[id]
+	if !ok {
+		return Item{}, ErrNotFound
+	}
+	return item, nil
+}
+
+// Items returns every item in the given state, oldest first.
+func (s *FileStore) Items(state State) []Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []Item
+	for _, item := range s.sorted() {
+		if item.State == state {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// DeadLetters returns the items that failed permanently, oldest first.
+func (s *FileStore) DeadLetters() []Item {
+	return s.Items(StateDead)
+}
+
+// Requeue moves a dead-lettered item back to pending with a fresh attempt
+// count, for example once the cause of the failure has been fixed. The item
+// is marked Requeued, so the worker still checks for an order placed by an
+// earlier attempt before ordering again.
+func (s *FileStore) Requeue(id string) error {
+	now := s.now()
+	return s.update(id, func(item *Item) error {
+		item.State = StatePending
+		item.Attempts = 0
+		item.Requeued = true
+		item.NextAttempt = now
+		return nil
+	})
+}
+
+func (s *FileStore) update(id string, change func(item *Item) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, ok := s.items[id]
+	if !ok {
+		return ErrNotFound
+	}
+	item := previous
+	if err := change(&item); err != nil {
+		return err
+	}
+	item.UpdatedAt = s.now()
+
+	s.items[id] = item
+	if err := s.save(); err != nil {
+		s.items[id] = previous
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) sorted() []Item {
+	items := make([]Item, 0, len(s.items))
+	for _, item := range s.items {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if !items[i].EnqueuedAt.Equal(items[j].EnqueuedAt) {
+			return items[i].EnqueuedAt.Before(items[j].EnqueuedAt)
+		}
+		return items[i].ID < items[j].ID
+	})
+	return items
+}
+
+// save writes every item to the file. The caller must hold s.mu.
+func (s *FileStore) save() error {
+	return jsonfile.Write(s.path, s.sorted())
+}
+
+func (s *FileStore) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,108 @@
+// Package outbox queues Tango orders for delivery by a background worker.
+//
+// An application enqueues an order with its own state change, and a Worker
+// later claims it, places it with the Tango API and records the result.
+// Orders are sent with an externalRefID, so a retry after an ambiguous
+// failure checks whether the earlier attempt went through before ordering
+// again. Orders that fail permanently, or run out of attempts, end up on a
+// dead-letter list instead of being retried forever.
+package outbox
+
+import (
+	"errors"
+	"time"
+
+	tango "github.com/c150pilot/go-tango-card"
+	"github.com/c150pilot/go-tango-card/internal/ids"
+)
+
+// State is where an item is in the outbox.
+type State string
+
+const (
+	// StatePending items are waiting to be claimed, possibly after NextAttempt.
+	StatePending State = "pending"
+	// StateClaimed items are being worked on until their lease expires.
+	StateClaimed State = "claimed"
+	// StateCompleted items have been ordered.
+	StateCompleted State = "completed"
+	// StateDead items failed permanently and will not be retried.
+	StateDead State = "dead"
+)
+
+var (
+	// ErrNotFound is returned by a Store for an unknown item ID.
+	ErrNotFound = errors.New("outbox: item not found")
+	// ErrLeaseLost is returned by Complete and Fail when the item has been
+	// claimed again since, or finished by another worker.
+	ErrLeaseLost = errors.New("outbox: lease lost")
+)
+
+// Item is a queued order.
+type Item struct {
+	ID    string                `json:"id"`
+	Order tango.CreateOrderData `json:"order"`
+	State State                 `json:"state"`
+
+	// Attempts counts how many times the item has been claimed since it was
+	// enqueued or last requeued.
+	Attempts int `json:"attempts"`
+	// Requeued is set once the item has been requeued from the dead-letter
+	// list, and is never cleared, since an earlier attempt may have placed the
+	// order.
+	Requeued    bool      `json:"requeued,omitempty"`
+	NextAttempt time.Time `json:"nextAttempt"`
+	// ClaimID identifies the current claim. Complete and Fail only succeed for
+	// the claim that is current.
+	ClaimID    string    `json:"claimID,omitempty"`
+	LeaseUntil time.Time `json:"leaseUntil,omitempty"`
+	LastError  string    `json:"lastError,omitempty"`
+
+	ReferenceOrderID string    `json:"referenceOrderID,omitempty"`
+	EnqueuedAt       time.Time `json:"enqueuedAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+/*
+Store holds the outbox. Implementations backed by the application's own
+database should let Enqueue run inside the caller's transaction, so an order
+is queued if and only if the surrounding change commits.
+
+Claim hands out up to limit pending items that are due at now, as well as
+claimed items whose lease has expired (their worker is presumed dead), marks
+them claimed with a new ClaimID and increments Attempts. An item must never be
+handed to two workers at once.
+
+Complete and Fail take the item as it was claimed and return ErrLeaseLost if
+that claim is no longer current, so a worker whose lease expired cannot
+overwrite the result of the worker that took over. Fail records a failed
+attempt: a zero retryAt moves the item to the dead-letter list; otherwise it
+becomes pending again from retryAt.
+*/
+type Store interface {
+	Enqueue(order tango.CreateOrderData) (Item, error)
+	Claim(now time.Time, limit int) ([]Item, error)
+	Complete(claimed Item, referenceOrderID string) error
+	Fail(claimed Item, reason string, retryAt time.Time) error
+}
+
+// NewItem returns a pending item for order, ready to be stored. An order
+// without an externalRefID is given the item ID, so that retries can be
+// matched to an earlier attempt.
+func NewItem(order tango.CreateOrderData, now time.Time) (Item, error) {
+	id, err := ids.New()
+	if err != nil {
+		return Item{}, err
+	}
+	if order.ExternalRefID == "" {
+		order.ExternalRefID = id
+	}
+	return Item{
+		ID:          id,
+		Order:       order,
+		State:       StatePending,
+		NextAttempt: now,
+		EnqueuedAt:  now,
+		UpdatedAt:   now,
+	}, nil
+}
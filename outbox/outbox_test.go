@@ -0,0 +1,319 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+type fakeClient struct {
+	mu      sync.Mutex
+	results []error
+	placed  map[string]string
+	orders  int
+	lookups int
+}
+
+func (f *fakeClient) Order(data tango.CreateOrderData) (tango.CreateOrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.orders++
+	var err error
+	if len(f.results) > 0 {
+		err, f.results = f.results[0], f.results[1:]
+	}
+	if err != nil {
+		return tango.CreateOrderResponse{}, err
+	}
+	if f.placed == nil {
+		f.placed = make(map[string]string)
+	}
+	f.placed[data.ExternalRefID] = "RA-" + data.ExternalRefID
+	return tango.CreateOrderResponse{ReferenceOrderID: "RA-" + data.ExternalRefID}, nil
+}
+
+func (f *fakeClient) FindOrdersByExternalRefID(externalRefID string) ([]tango.CreateOrderResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.lookups++
+	if id, ok := f.placed[externalRefID]; ok {
+		return []tango.CreateOrderResponse{{ReferenceOrderID: id, ExternalRefID: externalRefID}}, nil
+	}
+	return nil, nil
+}
+
+type testClock struct{ t time.Time }
+
+func (c *testClock) Now() time.Time { return c.t }
+
+func newTestWorker(t *testing.T, client *fakeClient) (*Worker, *FileStore, *testClock) {
+	t.Helper()
+	clock := &testClock{t: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)}
+	store, err := OpenFileStore(filepath.Join(t.TempDir(), "outbox.json"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	store.Now = clock.Now
+	worker := &Worker{
+		Store:   store,
+		Client:  client,
+		Backoff: func(int) time.Duration { return time.Minute },
+		Now:     clock.Now,
+	}
+	return worker, store, clock
+}
+
+func processNext(t *testing.T, w *Worker) bool {
+	t.Helper()
+	processed, err := w.ProcessNext()
+	if err != nil {
+		t.Fatalf("process: %v", err)
+	}
+	return processed
+}
+
+func TestWorker_CompletesOrder(t *testing.T) {
+	client := &fakeClient{}
+	worker, store, _ := newTestWorker(t, client)
+
+	item, err := store.Enqueue(tango.CreateOrderData{Utid: "U1", Amount: 10})
+	if err != nil {
+		t.Fatalf("enqueue: %v", err)
+	}
+	if item.Order.ExternalRefID != item.ID {
+		t.Fatalf("expected externalRefID to default to the item ID, got %q", item.Order.ExternalRefID)
+	}
+
+	if !processNext(t, worker) {
+		t.Fatal("expected an item to be processed")
+	}
+	if processNext(t, worker) {
+		t.Fatal("expected nothing left to process")
+	}
+
+	got, _ := store.Get(item.ID)
+	if got.State != StateCompleted || got.ReferenceOrderID != "RA-"+item.ID {
+		t.Fatalf("unexpected item: %+v", got)
+	}
+}
+
+func TestWorker_RetriesTemporaryErrors(t *testing.T) {
+	client := &fakeClient{results: []error{&tango.APIError{StatusCode: 503}}}
+	worker, store, clock := newTestWorker(t, client)
+
+	item, _ := store.Enqueue(tango.CreateOrderData{ExternalRefID: "ref-1"})
+
+	processNext(t, worker)
+	got, _ := store.Get(item.ID)
+	if got.State != StatePending || !got.NextAttempt.Equal(clock.t.Add(time.Minute)) {
+		t.Fatalf("expected a retry in a minute, got %+v", got)
+	}
+
+	if processNext(t, worker) {
+		t.Fatal("expected the retry to wait for its backoff")
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+	processNext(t, worker)
+	got, _ = store.Get(item.ID)
+	if got.State != StateCompleted || got.Attempts != 2 {
+		t.Fatalf("expected completion on the second attempt, got %+v", got)
+	}
+	if client.lookups != 1 {
+		t.Fatalf("expected the retry to look for an earlier order, got %d lookups", client.lookups)
+	}
+}
+
+func TestWorker_DeadLettersPermanentErrors(t *testing.T) {
+	client := &fakeClient{results: []error{&tango.APIError{StatusCode: 400}}}
+	worker, store, _ := newTestWorker(t, client)
+
+	item, _ := store.Enqueue(tango.CreateOrderData{ExternalRefID: "ref-1"})
+	processNext(t, worker)
+
+	dead := store.DeadLetters()
+	if len(dead) != 1 || dead[0].ID != item.ID || dead[0].LastError == "" {
+		t.Fatalf("expected the item to be dead-lettered, got %+v", dead)
+	}
+
+	if err := store.Requeue(item.ID); err != nil {
+		t.Fatalf("requeue: %v", err)
+	}
+	processNext(t, worker)
+	if got, _ := store.Get(item.ID); got.State != StateCompleted {
+		t.Fatalf("expected requeued item to complete, got %+v", got)
+	}
+}
+
+func TestWorker_DeadLettersAfterMaxAttempts(t *testing.T) {
+	netErr := &timeoutError{}
+	client := &fakeClient{results: []error{netErr, netErr}}
+	worker, store, clock := newTestWorker(t, client)
+	worker.MaxAttempts = 2
+
+	item, _ := store.Enqueue(tango.CreateOrderData{ExternalRefID: "ref-1"})
+	processNext(t, worker)
+	clock.t = clock.t.Add(time.Minute)
+	processNext(t, worker)
+
+	got, _ := store.Get(item.ID)
+	if got.State != StateDead || got.Attempts != 2 {
+		t.Fatalf("expected dead letter after 2 attempts, got %+v", got)
+	}
+}
+
+func TestWorker_DoesNotReorderAfterLostResult(t *testing.T) {
+	client := &fakeClient{}
+	worker, store, clock := newTestWorker(t, client)
+	store.Lease = time.Minute
+
+	item, _ := store.Enqueue(tango.CreateOrderData{ExternalRefID: "ref-1"})
+
+	// Simulate a worker that placed the order and then died before recording it
+	claimed, err := store.Claim(clock.t, 1)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("claim: %v, %v", claimed, err)
+	}
+	if _, err := client.Order(claimed[0].Order); err != nil {
+		t.Fatalf("order: %v", err)
+	}
+
+	if processNext(t, worker) {
+		t.Fatal("expected the item to stay leased")
+	}
+
+	clock.t = clock.t.Add(time.Minute)
+	processNext(t, worker)
+
+	got, _ := store.Get(item.ID)
+	if got.State != StateCompleted || got.ReferenceOrderID != "RA-ref-1" {
+		t.Fatalf("expected the earlier order to be recorded, got %+v", got)
+	}
+	if client.orders != 1 {
+		t.Fatalf("expected exactly one order to be placed, got %d", client.orders)
+	}
+}
+
+func TestWorker_RequeueChecksForEarlierOrder(t *testing.T) {
+	client := &fakeClient{}
+	worker, store, _ := newTestWorker(t, client)
+
+	item, _ := store.Enqueue(tango.CreateOrderData{ExternalRefID: "ref-1"})
+
+	// The order went through but the response was lost, and the item was
+	// dead-lettered on its first attempt
+	claimed, _ := store.Claim(worker.now(), 1)
+	if _, err := client.Order(claimed[0].Order); err != nil {
+		t.Fatalf("order: %v", err)
+	}
+	if err := store.Fail(claimed[0], "connection reset", time.Time{}); err != nil {
+		t.Fatalf("fail: %v", err)
+	}
+
+	if err := store.Requeue(item.ID); err != nil {
+		t.Fatalf("requeue: %v", err)
+	}
+	processNext(t, worker)
+
+	got, _ := store.Get(item.ID)
+	if got.State != StateCompleted || got.ReferenceOrderID != "RA-ref-1" || got.Attempts != 1 {
+		t.Fatalf("expected the earlier order to be recorded, got %+v", got)
+	}
+	if client.orders != 1 || client.lookups != 1 {
+		t.Fatalf("expected one order and one lookup, got %d and %d", client.orders, client.lookups)
+	}
+}
+
+func TestFileStore_LeaseLost(t *testing.T) {
+	client := &fakeClient{}
+	worker, store, clock := newTestWorker(t, client)
+	store.Lease = time.Minute
+
+	item, _ := store.Enqueue(tango.CreateOrderData{ExternalRefID: "ref-1"})
+	stale, _ := store.Claim(clock.t, 1)
+
+	// The lease runs out and another worker takes the item over
+	clock.t = clock.t.Add(time.Minute)
+	processNext(t, worker)
+
+	if err := store.Fail(stale[0], "timeout", time.Time{}); !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost, got %v", err)
+	}
+	if err := store.Complete(stale[0], "RA-other"); !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("expected ErrLeaseLost, got %v", err)
+	}
+	if got, _ := store.Get(item.ID); got.State != StateCompleted || got.ReferenceOrderID != "RA-ref-1" {
+		t.Fatalf("expected the new worker's result to stand, got %+v", got)
+	}
+}
+
+func TestFileStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outbox.json")
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	item, _ := store.Enqueue(tango.CreateOrderData{ExternalRefID: "ref-1"})
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	got, err := reopened.Get(item.ID)
+	if err != nil || got.Order.ExternalRefID != "ref-1" || got.State != StatePending {
+		t.Fatalf("unexpected item after reopen: %+v, %v", got, err)
+	}
+
+	if err := reopened.Complete(Item{ID: "missing"}, ""); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestWorker_RunStopsOnCancel(t *testing.T) {
+	client := &fakeClient{}
+	worker, store, _ := newTestWorker(t, client)
+	worker.Now = nil
+	worker.PollInterval = time.Millisecond
+	store.Now = nil
+
+	item, _ := store.Enqueue(tango.CreateOrderData{ExternalRefID: "ref-1"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- worker.Run(ctx) }()
+
+	deadline := time.After(2 * time.Second)
+	for {
+		if got, _ := store.Get(item.ID); got.State == StateCompleted {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("item was not processed")
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after cancel")
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
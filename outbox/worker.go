@@ -0,0 +1,158 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	tango "github.com/c150pilot/go-tango-card"
+	"github.com/c150pilot/go-tango-card/internal/retry"
+)
+
+const (
+	// DefaultMaxAttempts is how many times an item is tried before it is
+	// dead-lettered.
+	DefaultMaxAttempts = 8
+	// DefaultPollInterval is how long an idle worker waits before checking
+	// the store again.
+	DefaultPollInterval = 5 * time.Second
+)
+
+// Client is implemented by *tango.TangoClient.
+type Client interface {
+	Order(data tango.CreateOrderData) (tango.CreateOrderResponse, error)
+	FindOrdersByExternalRefID(externalRefID string) ([]tango.CreateOrderResponse, error)
+}
+
+// Worker delivers the orders in a Store one at a time.
+type Worker struct {
+	Store  Store
+	Client Client
+
+	// MaxAttempts is how many times an item is tried before it is
+	// dead-lettered. Zero uses DefaultMaxAttempts.
+	MaxAttempts int
+	// Backoff returns how long to wait before the next try after a failed
+	// attempt (1 for the first). Nil uses DefaultBackoff.
+	Backoff func(attempt int) time.Duration
+	// PollInterval is how long to wait when there is nothing to do. Zero uses
+	// DefaultPollInterval.
+	PollInterval time.Duration
+	// Now is the worker's clock. Nil uses time.Now.
+	Now func() time.Time
+}
+
+// DefaultBackoff waits 10 seconds after the first failure and doubles with
+// each attempt, up to 30 minutes.
+func DefaultBackoff(attempt int) time.Duration {
+	return retry.Backoff(attempt)
+}
+
+/*
+Run processes items until ctx is cancelled, then returns nil. Shutdown is
+graceful: an order that is already in flight is allowed to finish and its
+result is recorded before Run returns, and no new item is claimed after ctx is
+done.
+
+Run returns early with an error only if the store fails.
+*/
+func (w *Worker) Run(ctx context.Context) error {
+	interval := w.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	for ctx.Err() == nil {
+		processed, err := w.ProcessNext()
+		if err != nil {
+			return err
+		}
+		if processed {
+			continue
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
+
+// ProcessNext claims and processes a single item. It reports false if no item
+// was due.
+func (w *Worker) ProcessNext() (bool, error) {
+	items, err := w.Store.Claim(w.now(), 1)
+	if err != nil {
+		return false, fmt.Errorf("outbox: claim: %w", err)
+	}
+	if len(items) == 0 {
+		return false, nil
+	}
+	return true, w.process(items[0])
+}
+
+func (w *Worker) process(item Item) error {
+	// A previous attempt may have placed the order even though it did not
+	// get as far as recording it, so look before ordering again.
+	if (item.Attempts > 1 || item.Requeued) && item.Order.ExternalRefID != "" {
+		orders, err := w.Client.FindOrdersByExternalRefID(item.Order.ExternalRefID)
+		if err != nil {
+			return w.fail(item, fmt.Errorf("looking up earlier attempt: %w", err))
+		}
+		if len(orders) > 0 {
+			return w.complete(item, orders[0].ReferenceOrderID)
+		}
+	}
+
+	resp, err := w.Client.Order(item.Order)
+	if err != nil {
+		return w.fail(item, err)
+	}
+	return w.complete(item, resp.ReferenceOrderID)
+}
+
+// complete records the order. If the lease was lost, the worker that took the
+// item over owns its result, and it will find this order by externalRefID.
+func (w *Worker) complete(item Item, referenceOrderID string) error {
+	if err := w.Store.Complete(item, referenceOrderID); err != nil && !errors.Is(err, ErrLeaseLost) {
+		return fmt.Errorf("outbox: complete %s: %w", item.ID, err)
+	}
+	return nil
+}
+
+func (w *Worker) fail(item Item, err error) error {
+	maxAttempts := w.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	var retryAt time.Time
+	reason := err.Error()
+	switch {
+	case !retry.Retryable(err):
+	case item.Attempts >= maxAttempts:
+		reason = fmt.Sprintf("giving up after %d attempts: %v", item.Attempts, err)
+	default:
+		backoff := w.Backoff
+		if backoff == nil {
+			backoff = DefaultBackoff
+		}
+		retryAt = w.now().Add(backoff(item.Attempts))
+	}
+
+	if err := w.Store.Fail(item, reason, retryAt); err != nil && !errors.Is(err, ErrLeaseLost) {
+		return fmt.Errorf("outbox: fail %s: %w", item.ID, err)
+	}
+	return nil
+}
+
+func (w *Worker) now() time.Time {
+	if w.Now != nil {
+		return w.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,262 @@
+package tango
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// PhoneNumber is a phone number split into its country calling code and
+// national significant number.
+type PhoneNumber struct {
+	// Region is the ISO 3166-1 alpha-2 code of the country the number belongs to.
+	// Numbers in the North American Numbering Plan report the region they were
+	// parsed for, or "US" when that is ambiguous.
+	Region         string
+	CountryCode    string
+	NationalNumber string
+}
+
+type phoneRegion struct {
+	countryCode string
+	minLength   int
+	maxLength   int
+	// trunkPrefix is dialled before national numbers within the country and
+	// dropped from the international form.
+	trunkPrefix string
+	// validLeading lists the digits a national number may start with, or "" for any.
+	validLeading string
+}
+
+// phoneRegions holds the numbering rules for the countries Tango delivers SMS rewards to.
+var phoneRegions = map[string]phoneRegion{
+	"US": {countryCode: "1", minLength: 10, maxLength: 10, trunkPrefix: "1", validLeading: "23456789"},
+	"CA": {countryCode: "1", minLength: 10, maxLength: 10, trunkPrefix: "1", validLeading: "23456789"},
+	"PR": {countryCode: "1", minLength: 10, maxLength: 10, trunkPrefix: "1", validLeading: "23456789"},
+	"GB": {countryCode: "44", minLength: 9, maxLength: 10, trunkPrefix: "0", validLeading: "123578"},
+	"IE": {countryCode: "353", minLength: 7, maxLength: 9, trunkPrefix: "0", validLeading: "12456789"},
+	"DE": {countryCode: "49", minLength: 6, maxLength: 13, trunkPrefix: "0", validLeading: "123456789"},
+	"FR": {countryCode: "33", minLength: 9, maxLength: 9, trunkPrefix: "0", validLeading: "123456789"},
+	"ES": {countryCode: "34", minLength: 9, maxLength: 9, validLeading: "56789"},
+	"IT": {countryCode: "39", minLength: 6, maxLength: 11},
+	"NL": {countryCode: "31", minLength: 9, maxLength: 9, trunkPrefix: "0", validLeading: "123456789"},
+	"BE": {countryCode: "32", minLength: 8, maxLength: 9, trunkPrefix: "0", validLeading: "123456789"},
+	"CH": {countryCode: "41", minLength: 9, maxLength: 9, trunkPrefix: "0", validLeading: "2345789"},
+	"AT": {countryCode: "43", minLength: 4, maxLength: 13, trunkPrefix: "0", validLeading: "123456789"},
+	"SE": {countryCode: "46", minLength: 7, maxLength: 9, trunkPrefix: "0", validLeading: "123456789"},
+	"NO": {countryCode: "47", minLength: 8, maxLength: 8, validLeading: "234579"},
+	"DK": {countryCode: "45", minLength: 8, maxLength: 8, validLeading: "23456789"},
+	"FI": {countryCode: "358", minLength: 5, maxLength: 12, trunkPrefix: "0", validLeading: "123456789"},
+	"PL": {countryCode: "48", minLength: 9, maxLength: 9, validLeading: "123456789"},
+	"PT": {countryCode: "351", minLength: 9, maxLength: 9, validLeading: "2789"},
+	"AU": {countryCode: "61", minLength: 9, maxLength: 9, trunkPrefix: "0", validLeading: "2345789"},
+	"NZ": {countryCode: "64", minLength: 8, maxLength: 10, trunkPrefix: "0", validLeading: "234679"},
+	"JP": {countryCode: "81", minLength: 9, maxLength: 10, trunkPrefix: "0", validLeading: "123456789"},
+	"IN": {countryCode: "91", minLength: 10, maxLength: 10, trunkPrefix: "0", validLeading: "123456789"},
+	"MX": {countryCode: "52", minLength: 10, maxLength: 10, validLeading: "123456789"},
+	"BR": {countryCode: "55", minLength: 10, maxLength: 11, trunkPrefix: "0", validLeading: "123456789"},
+	"SG": {countryCode: "65", minLength: 8, maxLength: 8, validLeading: "3689"},
+	"HK": {countryCode: "852", minLength: 8, maxLength: 8, validLeading: "2345679"},
+	"ZA": {countryCode: "27", minLength: 9, maxLength: 9, trunkPrefix: "0", validLeading: "12345678"},
+}
+
+/*
+ParsePhoneNumber parses a phone number and checks it against the numbering rules
+of its country.
+
+Numbers in international form ("+44 20 7946 0958" or "0044 20 7946 0958") are
+accepted for any supported country. Numbers in national form ("(212) 555-0100",
+"020 7946 0958") are read as belonging to defaultRegion, an ISO 3166-1 alpha-2
+code. Spaces, dots, dashes and parentheses are ignored.
+
+Errors never contain the full number.
+*/
+func ParsePhoneNumber(number, defaultRegion string) (PhoneNumber, error) {
+	defaultRegion = strings.ToUpper(strings.TrimSpace(defaultRegion))
+
+	digits, international, err := phoneDigits(number)
+	if err != nil {
+		return PhoneNumber{}, err
+	}
+	if !international && strings.HasPrefix(digits, "00") {
+		digits, international = digits[2:], true
+	} else if !international && strings.HasPrefix(digits, "011") && phoneRegions[defaultRegion].countryCode == "1" {
+		digits, international = digits[3:], true
+	}
+
+	if international {
+		return parseInternationalPhoneNumber(number, digits, defaultRegion)
+	}
+
+	if defaultRegion == "" {
+		return PhoneNumber{}, fmt.Errorf("phone number %s must be in international format (+country code) when no region is given", RedactPhoneNumber(number))
+	}
+	region, ok := phoneRegions[defaultRegion]
+	if !ok {
+		return PhoneNumber{}, fmt.Errorf("unsupported phone number region %q", defaultRegion)
+	}
+
+	// A "0" trunk prefix is never part of the national number; NANP's "1" only
+	// counts as a prefix when it makes the number too long.
+	national := digits
+	if region.trunkPrefix != "" && strings.HasPrefix(national, region.trunkPrefix) &&
+		(region.trunkPrefix == "0" || len(national) > region.maxLength) {
+		national = strings.TrimPrefix(national, region.trunkPrefix)
+	}
+
+	return newPhoneNumber(number, defaultRegion, region, national)
+}
+
+func parseInternationalPhoneNumber(raw, digits, defaultRegion string) (PhoneNumber, error) {
+	// Country calling codes are prefix-free and at most three digits long.
+	for length := 1; length <= 3 && length < len(digits); length++ {
+		if name, ok := regionForCountryCode(digits[:length], defaultRegion); ok {
+			return newPhoneNumber(raw, name, phoneRegions[name], digits[length:])
+		}
+	}
+
+	return PhoneNumber{}, fmt.Errorf("phone number %s has an unsupported country calling code", RedactPhoneNumber(raw))
+}
+
+// regionForCountryCode prefers defaultRegion when it shares the calling code,
+// which only happens within the North American Numbering Plan.
+func regionForCountryCode(countryCode, defaultRegion string) (string, bool) {
+	if region, ok := phoneRegions[defaultRegion]; ok && region.countryCode == countryCode {
+		return defaultRegion, true
+	}
+	if countryCode == "1" {
+		return "US", true
+	}
+	for name, region := range phoneRegions {
+		if region.countryCode == countryCode {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func newPhoneNumber(raw, regionName string, region phoneRegion, national string) (PhoneNumber, error) {
+	if len(national) < region.minLength || len(national) > region.maxLength {
+		return PhoneNumber{}, fmt.Errorf("phone number %s has the wrong number of digits for %s", RedactPhoneNumber(raw), regionName)
+	}
+	if region.validLeading != "" && !strings.ContainsRune(region.validLeading, rune(national[0])) {
+		return PhoneNumber{}, fmt.Errorf("phone number %s is not a valid %s number", RedactPhoneNumber(raw), regionName)
+	}
+	// NANP exchange codes cannot start with 0 or 1 either.
+	if region.countryCode == "1" && (national[3] == '0' || national[3] == '1') {
+		return PhoneNumber{}, fmt.Errorf("phone number %s is not a valid %s number", RedactPhoneNumber(raw), regionName)
+	}
+
+	return PhoneNumber{
+		Region:         regionName,
+		CountryCode:    region.countryCode,
+		NationalNumber: national,
+	}, nil
+}
+
+func phoneDigits(number string) (digits string, international bool, err error) {
+	number = strings.TrimSpace(number)
+	if number == "" {
+		return "", false, fmt.Errorf("phone number is required")
+	}
+
+	var b strings.Builder
+	for i, r := range number {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '+' && i == 0:
+			international = true
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", false, fmt.Errorf("phone number %s contains invalid characters", RedactPhoneNumber(number))
+		}
+	}
+
+	digits = b.String()
+	if len(digits) == 0 {
+		return "", false, fmt.Errorf("phone number %s contains no digits", RedactPhoneNumber(number))
+	}
+	return digits, international, nil
+}
+
+// E164 returns the number in E.164 format, e.g. "+442079460958".
+func (p PhoneNumber) E164() string {
+	if p.NationalNumber == "" {
+		return ""
+	}
+	return "+" + p.CountryCode + p.NationalNumber
+}
+
+// String and LogValue return the number redacted, so it is safe to print and
+// log. Use E164 for the full number.
+func (p PhoneNumber) String() string {
+	return RedactPhoneNumber(p.E164())
+}
+
+func (p PhoneNumber) LogValue() slog.Value {
+	return slog.StringValue(p.String())
+}
+
+/*
+RedactPhoneNumber masks every digit of a phone number except the last two,
+keeping a leading "+", e.g. "+1*********00".
+*/
+func RedactPhoneNumber(number string) string {
+	var digits []rune
+	prefix := ""
+	for i, r := range strings.TrimSpace(number) {
+		if r == '+' && i == 0 {
+			prefix = "+"
+		}
+		if r >= '0' && r <= '9' {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) == 0 {
+		return prefix
+	}
+
+	visible := 2
+	if len(digits) <= 4 {
+		visible = 0
+	}
+	return prefix + strings.Repeat("*", len(digits)-visible) + string(digits[len(digits)-visible:])
+}
+
+// redactPhoneNumberIn masks any occurrence of number, in E.164 or digit-only
+// form, inside s.
+func redactPhoneNumberIn(s, number string) string {
+	if number == "" {
+		return s
+	}
+	redacted := RedactPhoneNumber(number)
+	s = strings.ReplaceAll(s, number, redacted)
+	return strings.ReplaceAll(s, strings.TrimPrefix(number, "+"), strings.TrimPrefix(redacted, "+"))
+}
+
+// LogValue implements slog.LogValuer so recipient phone numbers are redacted in
+// structured logs.
+func (p Person) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.String("firstName", p.FirstName),
+		slog.String("lastName", p.LastName),
+		slog.String("email", p.Email),
+	}
+	if p.PhoneNumber != "" {
+		attrs = append(attrs, slog.String("phoneNumber", RedactPhoneNumber(p.PhoneNumber)))
+	}
+	if p.Address != (Address{}) {
+		attrs = append(attrs, slog.Any("address", p.Address))
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// recipientPhoneRegion uses the recipient's address country, when it is a
+// two-letter code, as the region for phone numbers in national format.
+func recipientPhoneRegion(p Person) string {
+	if country := strings.TrimSpace(p.Address.Country); len(country) == 2 {
+		return strings.ToUpper(country)
+	}
+	return ""
+}
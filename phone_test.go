@@ -0,0 +1,115 @@
+package tango
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParsePhoneNumber(t *testing.T) {
+	tests := []struct {
+		number     string
+		region     string
+		want       string
+		wantRegion string
+	}{
+		{"+1 (212) 555-0100", "", "+12125550100", "US"},
+		{"(212) 555-0100", "us", "+12125550100", "US"},
+		{"1-416-555-0100", "CA", "+14165550100", "CA"},
+		{"011 44 20 7946 0958", "US", "+442079460958", "GB"},
+		{"020 7946 0958", "GB", "+442079460958", "GB"},
+		{"0044 7700 900123", "", "+447700900123", "GB"},
+		{"+61 412 345 678", "", "+61412345678", "AU"},
+		{"0412 345 678", "AU", "+61412345678", "AU"},
+		{"+49 30 123456", "", "+4930123456", "DE"},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePhoneNumber(tt.number, tt.region)
+		if err != nil {
+			t.Fatalf("ParsePhoneNumber(%q, %q) returned error: %v", tt.number, tt.region, err)
+		}
+		if got.E164() != tt.want || got.Region != tt.wantRegion {
+			t.Fatalf("ParsePhoneNumber(%q, %q) = %s (%s), want %s (%s)", tt.number, tt.region, got.E164(), got.Region, tt.want, tt.wantRegion)
+		}
+	}
+}
+
+func TestParsePhoneNumber_Invalid(t *testing.T) {
+	tests := []struct {
+		number string
+		region string
+	}{
+		{"", "US"},
+		{"212 555 0100", ""},
+		{"212 555 0100", "XX"},
+		{"+1 212 055 0100", ""},
+		{"+1 112 555 0100", ""},
+		{"+1 212 555 010", ""},
+		{"+44 20 7946 09581", ""},
+		{"+999 1234 5678", ""},
+		{"212-555-0100 ext 5", "US"},
+	}
+
+	for _, tt := range tests {
+		_, err := ParsePhoneNumber(tt.number, tt.region)
+		if err == nil {
+			t.Fatalf("ParsePhoneNumber(%q, %q) expected error", tt.number, tt.region)
+		}
+		if strings.Contains(err.Error(), "555") && strings.Contains(err.Error(), "0100") {
+			t.Fatalf("error leaks phone number: %v", err)
+		}
+	}
+}
+
+func TestRedactPhoneNumber(t *testing.T) {
+	if got := RedactPhoneNumber("+12125550100"); got != "+*********00" {
+		t.Fatalf("unexpected redaction: %s", got)
+	}
+	if got := RedactPhoneNumber("123"); got != "***" {
+		t.Fatalf("unexpected redaction of short number: %s", got)
+	}
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	logger.Info("order", "recipient", Person{FirstName: "Ada", PhoneNumber: "+12125550100"})
+	if strings.Contains(buf.String(), "2125550100") {
+		t.Fatalf("log leaks phone number: %s", buf.String())
+	}
+}
+
+func TestOrder_PhoneDelivery(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		buf := new(bytes.Buffer)
+		_, _ = buf.ReadFrom(r.Body)
+		body = buf.String()
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"httpCode":400,"errors":[{"path":"recipient.phoneNumber","message":"unreachable","invalidValue":"+12125550100"}]}`))
+	}))
+	defer server.Close()
+
+	originalURL := ApiURL
+	ApiURL = server.URL
+	defer func() { ApiURL = originalURL }()
+
+	order, err := NewOrderBuilder("cust", "U1").Amount(5).ToPhone("Ada", "Lovelace", "+1 (212) 555-0100").Build()
+	if err != nil {
+		t.Fatalf("build failed: %v", err)
+	}
+
+	client := &TangoClient{Token: "token", AccountIdentifier: "acct"}
+	_, err = client.Order(order)
+	if err == nil {
+		t.Fatalf("expected error from API")
+	}
+	if !strings.Contains(body, `"phoneNumber":"+12125550100"`) {
+		t.Fatalf("expected E.164 phone number in payload, got %s", body)
+	}
+	if strings.Contains(err.Error(), "2125550100") {
+		t.Fatalf("error leaks phone number: %v", err)
+	}
+}
@@ -0,0 +1,193 @@
+package tango
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// OrderPreview describes an order that PreviewOrder built but did not submit.
+type OrderPreview struct {
+	// Payload is the exact JSON body Order would POST.
+	Payload json.RawMessage
+	Brand   Brand
+	Item    Item
+	Cost    OrderCost
+}
+
+/*
+OrderCost is the expected cost of an order.
+
+The reward is issued in RewardCurrency; the account is charged in
+AccountCurrency. ExchangeRate is the number of reward currency units per
+account currency unit, and is 1 when both currencies match. Value, Fee and
+Total are in the account currency, rounded to cents.
+*/
+type OrderCost struct {
+	FaceValue       float64
+	RewardCurrency  string
+	AccountCurrency string
+	ExchangeRate    float64
+	Value           float64
+	Fee             float64
+	Total           float64
+}
+
+/*
+PreviewOrder builds the payload Order would send, validates it against the
+catalog and works out what the order would cost, without placing it.
+
+It fetches the catalog, the account and, for rewards in another currency, the
+exchange rate. With CheckEmailTemplates set, the Etid is looked up first, as
+Order does. Tango asks that the catalog not be fetched per order, so when
+previewing many orders fetch it once and use PreviewOrderWithCatalog.
+*/
+func (c *TangoClient) PreviewOrder(data CreateOrderData) (OrderPreview, error) {
+	catalog, err := c.GetCatalogItems()
+	if err != nil {
+		return OrderPreview{}, err
+	}
+	return c.PreviewOrderWithCatalog(data, catalog)
+}
+
+// PreviewOrderWithCatalog is PreviewOrder using a catalog the caller already has.
+func (c *TangoClient) PreviewOrderWithCatalog(data CreateOrderData, catalog Catalog) (OrderPreview, error) {
+	if err := c.checkOrderEmailTemplate(data); err != nil {
+		return OrderPreview{}, err
+	}
+
+	payload, err := c.newOrderRequest(data)
+	if err != nil {
+		return OrderPreview{}, err
+	}
+	payloadJSON, err := marshalOrderRequest(payload)
+	if err != nil {
+		return OrderPreview{}, err
+	}
+
+	var errs ValidationErrors
+	if err := data.Validate(); err != nil {
+		var validationErrs ValidationErrors
+		if !errors.As(err, &validationErrs) {
+			return OrderPreview{}, err
+		}
+		errs = append(errs, validationErrs...)
+	}
+
+	brand, item, found := catalog.FindItem(data.Utid)
+	if !found {
+		if data.Utid != "" {
+			errs = append(errs, FieldError{Field: "utid", Message: fmt.Sprintf("%q is not in the catalog", data.Utid)})
+		}
+	} else {
+		errs = append(errs, checkItemAmount(item, data.Amount)...)
+	}
+	if len(errs) > 0 {
+		return OrderPreview{}, errs
+	}
+
+	account, err := c.GetAccountInfo(c.AccountIdentifier)
+	if err != nil {
+		return OrderPreview{}, err
+	}
+
+	rate := 1.0
+	if !strings.EqualFold(account.CurrencyCode, item.CurrencyCode) {
+		rate, err = c.exchangeRate(account.CurrencyCode, item.CurrencyCode)
+		if err != nil {
+			return OrderPreview{}, err
+		}
+	}
+
+	return OrderPreview{
+		Payload: payloadJSON,
+		Brand:   brand,
+		Item:    item,
+		Cost:    orderCost(item, data.Amount, account.CurrencyCode, rate),
+	}, nil
+}
+
+// FindItem returns the catalog item with the given UTID and the brand it belongs to.
+func (c Catalog) FindItem(utid string) (Brand, Item, bool) {
+	for _, brand := range c.Brands {
+		for _, item := range brand.Items {
+			if item.Utid == utid {
+				return brand, item, true
+			}
+		}
+	}
+	return Brand{}, Item{}, false
+}
+
+func checkItemAmount(item Item, amount float64) ValidationErrors {
+	var errs ValidationErrors
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, FieldError{Field: "amount", Message: fmt.Sprintf(format, args...)})
+	}
+
+	if !strings.EqualFold(item.Status, "active") {
+		errs = append(errs, FieldError{Field: "utid", Message: fmt.Sprintf("item %s is %s", item.Utid, strings.ToLower(item.Status))})
+	}
+
+	if strings.EqualFold(item.ValueType, "FIXED_VALUE") {
+		if amount != item.FaceValue {
+			fail("must be %v %s for this fixed-value item", item.FaceValue, item.CurrencyCode)
+		}
+	} else {
+		if item.MinValue > 0 && amount < item.MinValue {
+			fail("must be at least %v %s", item.MinValue, item.CurrencyCode)
+		}
+		if item.MaxValue > 0 && amount > item.MaxValue {
+			fail("must be at most %v %s", item.MaxValue, item.CurrencyCode)
+		}
+	}
+	if item.IsWholeAmountValueRequired && amount != math.Trunc(amount) {
+		fail("must be a whole amount for this item")
+	}
+
+	return errs
+}
+
+// exchangeRate returns how many units of rewardCurrency one unit of baseCurrency buys.
+func (c *TangoClient) exchangeRate(baseCurrency, rewardCurrency string) (float64, error) {
+	rates, err := c.GetExchangeRates(baseCurrency, rewardCurrency)
+	if err != nil {
+		return 0, err
+	}
+	for _, rate := range rates.ExchangeRates {
+		if strings.EqualFold(rate.BaseCurrency, baseCurrency) && strings.EqualFold(rate.RewardCurrency, rewardCurrency) && rate.BaseFx > 0 {
+			return rate.BaseFx, nil
+		}
+	}
+	return 0, fmt.Errorf("no exchange rate from %s to %s", baseCurrency, rewardCurrency)
+}
+
+// orderCost applies the exchange rate and the item's fee. Percentage fees are
+// charged on the converted value; flat fees are taken to be in the account currency.
+func orderCost(item Item, amount float64, accountCurrency string, rate float64) OrderCost {
+	value := amount / rate
+
+	var fee float64
+	switch strings.ToUpper(item.Fee.Type) {
+	case "PERCENTAGE", "PERCENT":
+		fee = value * item.Fee.Value / 100
+	default:
+		fee = item.Fee.Value
+	}
+
+	return OrderCost{
+		FaceValue:       amount,
+		RewardCurrency:  item.CurrencyCode,
+		AccountCurrency: accountCurrency,
+		ExchangeRate:    rate,
+		Value:           roundCents(value),
+		Fee:             roundCents(fee),
+		Total:           roundCents(value + fee),
+	}
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
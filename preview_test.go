@@ -0,0 +1,117 @@
+package tango
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+const previewCatalog = `{"catalogName":"test","brands":[{"brandKey":"B1","brandName":"Coffee","items":[
+	{"utid":"U-USD","rewardName":"Coffee USD","currencyCode":"USD","status":"active","valueType":"VARIABLE_VALUE","minValue":5,"maxValue":100,"fee":{"type":"PERCENTAGE","value":2.5}},
+	{"utid":"U-EUR","rewardName":"Coffee EUR","currencyCode":"EUR","status":"active","valueType":"FIXED_VALUE","faceValue":20,"isWholeAmountValueRequired":true,"fee":{"type":"FLAT","value":1}},
+	{"utid":"U-OFF","rewardName":"Gone","currencyCode":"USD","status":"inactive","valueType":"VARIABLE_VALUE","minValue":1,"maxValue":10}
+]}]}`
+
+func previewServer(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("preview must not submit anything, got %s %s", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		switch r.URL.Path {
+		case "/catalogs":
+			_, _ = w.Write([]byte(previewCatalog))
+		case "/accounts/acct":
+			_, _ = w.Write([]byte(`{"accountIdentifier":"acct","currencyCode":"USD"}`))
+		case "/exchangerates":
+			if r.URL.Query().Get("baseCurrency") != "USD" || r.URL.Query().Get("rewardCurrency") != "EUR" {
+				t.Errorf("unexpected exchange rate query %s", r.URL.RawQuery)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"exchangeRates":[{"baseCurrency":"USD","rewardCurrency":"EUR","baseFx":0.8}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+}
+
+func TestPreviewOrder(t *testing.T) {
+	previewServer(t)
+	client := &TangoClient{Token: "token", AccountIdentifier: "acct"}
+
+	preview, err := client.PreviewOrder(CreateOrderData{
+		CustomerIdentifier: "cust",
+		Utid:               "U-USD",
+		Amount:             40,
+		DeliveryMethod:     DeliveryMethodEmbedded,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if preview.Cost.Value != 40 || preview.Cost.Fee != 1 || preview.Cost.Total != 41 || preview.Cost.ExchangeRate != 1 {
+		t.Fatalf("unexpected cost: %+v", preview.Cost)
+	}
+	if preview.Brand.BrandName != "Coffee" {
+		t.Fatalf("unexpected brand: %+v", preview.Brand)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(preview.Payload, &payload); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	if payload["accountIdentifier"] != "acct" || payload["utid"] != "U-USD" || payload["amount"] != 40.0 {
+		t.Fatalf("unexpected payload: %s", preview.Payload)
+	}
+
+	preview, err = client.PreviewOrder(CreateOrderData{
+		CustomerIdentifier: "cust",
+		Utid:               "U-EUR",
+		Amount:             20,
+		DeliveryMethod:     DeliveryMethodEmbedded,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if preview.Cost.Value != 25 || preview.Cost.Fee != 1 || preview.Cost.Total != 26 || preview.Cost.AccountCurrency != "USD" {
+		t.Fatalf("unexpected converted cost: %+v", preview.Cost)
+	}
+}
+
+func TestPreviewOrder_CatalogValidation(t *testing.T) {
+	previewServer(t)
+	client := &TangoClient{Token: "token", AccountIdentifier: "acct"}
+
+	tests := map[string]CreateOrderData{
+		"below minimum":      {CustomerIdentifier: "cust", Utid: "U-USD", Amount: 1},
+		"fixed value":        {CustomerIdentifier: "cust", Utid: "U-EUR", Amount: 25},
+		"inactive item":      {CustomerIdentifier: "cust", Utid: "U-OFF", Amount: 5},
+		"not in the catalog": {CustomerIdentifier: "cust", Utid: "U-NOPE", Amount: 5},
+	}
+	for name, data := range tests {
+		_, err := client.PreviewOrder(data)
+		var errs ValidationErrors
+		if !errors.As(err, &errs) {
+			t.Fatalf("%s: expected validation errors, got %v", name, err)
+		}
+	}
+}
+
+func TestPreviewOrder_CheckEmailTemplates(t *testing.T) {
+	previewServer(t)
+	client := &TangoClient{Token: "token", AccountIdentifier: "acct", CheckEmailTemplates: true}
+
+	_, err := client.PreviewOrder(CreateOrderData{
+		CustomerIdentifier: "cust",
+		Utid:               "U-USD",
+		Amount:             40,
+		Etid:               "E-MISSING",
+		DeliveryMethod:     DeliveryMethodEmail,
+		Recipient:          Person{FirstName: "Ada", Email: "ada@example.com"},
+	})
+	if !errors.Is(err, ErrUnknownEmailTemplate) {
+		t.Fatalf("expected the preview to reject the template Order would reject, got %v", err)
+	}
+}
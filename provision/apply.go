@@ -0,0 +1,49 @@
+package provision
+
+import (
+	"errors"
+	"fmt"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+/*
+Apply carries out the creates in a plan, in order, and returns the ones that
+succeeded. Drift and unmanaged resources are left alone.
+
+Customers and accounts are created with EnsureCustomer and
+EnsureCustomerAccount, so one created since the plan was made, for example
+by an earlier Apply that failed part way, is not an error. Apply stops at the
+first failure; build a new plan and apply it again to carry on.
+*/
+func Apply(client Client, plan Plan) (applied []Change, err error) {
+	for _, change := range plan.Creates() {
+		if err := apply(client, change); err != nil {
+			return applied, fmt.Errorf("create %s %s: %w", change.Kind, change.Path(), err)
+		}
+		applied = append(applied, change)
+	}
+	return applied, nil
+}
+
+func apply(client Client, change Change) error {
+	switch change.Kind {
+	case KindCustomer:
+		_, _, err := client.EnsureCustomer(change.Customer, change.DisplayName)
+		return err
+	case KindAccount:
+		_, _, err := client.EnsureCustomerAccount(change.Customer, change.Account, change.DisplayName, change.ContactEmail)
+		return err
+	case KindLowBalanceAlert:
+		if change.Alert == nil {
+			return errors.New("no alert settings")
+		}
+		_, err := client.CreateLowBalanceAlert(change.Account, tango.LowBalanceAlertRequest{
+			BalanceThreshold: change.Alert.Threshold,
+			EmailsToNotify:   change.Alert.Emails,
+		})
+		return err
+	default:
+		return fmt.Errorf("unknown kind %q", change.Kind)
+	}
+}
@@ -0,0 +1,335 @@
+package provision
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	tango "github.com/c150pilot/go-tango-card"
+	"github.com/c150pilot/go-tango-card/internal/emails"
+)
+
+// Client is implemented by *tango.TangoClient.
+type Client interface {
+	GetCustomers() ([]tango.Customer, error)
+	GetCustomerAccounts(customerIdentifier string) ([]tango.UserAccount, error)
+	GetLowBalanceAlerts(accountIdentifier string) ([]tango.LowBalanceAlert, error)
+	EnsureCustomer(customerIdentifier string, displayName string) (tango.Customer, bool, error)
+	EnsureCustomerAccount(customerIdentifier string, accountIdentifier string, displayName string, contactEmail string) (tango.Account, bool, error)
+	CreateLowBalanceAlert(accountIdentifier string, data tango.LowBalanceAlertRequest) (tango.LowBalanceAlert, error)
+}
+
+type Action string
+
+const (
+	// ActionCreate is a resource in the spec that does not exist yet. It is
+	// the only action Apply carries out.
+	ActionCreate Action = "create"
+	// ActionDrift is a resource that exists but differs from the spec.
+	ActionDrift Action = "drift"
+	// ActionUnmanaged is a resource that exists but is not in the spec.
+	ActionUnmanaged Action = "unmanaged"
+)
+
+type Kind string
+
+const (
+	KindCustomer        Kind = "customer"
+	KindAccount         Kind = "account"
+	KindLowBalanceAlert Kind = "lowBalanceAlert"
+)
+
+// Change is one difference between the spec and the platform.
+type Change struct {
+	Action   Action `json:"action"`
+	Kind     Kind   `json:"kind"`
+	Customer string `json:"customer"`
+	Account  string `json:"account,omitempty"`
+	// Detail is a human readable description of the change.
+	Detail string `json:"detail"`
+
+	// The desired values, set on creates.
+	DisplayName  string     `json:"displayName,omitempty"`
+	ContactEmail string     `json:"contactEmail,omitempty"`
+	Alert        *AlertSpec `json:"alert,omitempty"`
+}
+
+/*
+Plan is the list of differences between a spec and the platform, in the order
+they would be applied: a customer comes before its accounts, and an account
+before its low balance alerts.
+*/
+type Plan struct {
+	Changes []Change `json:"changes"`
+}
+
+// Creates returns the changes Apply would carry out.
+func (p Plan) Creates() []Change {
+	return p.filter(ActionCreate)
+}
+
+// Drift returns the resources that differ from the spec.
+func (p Plan) Drift() []Change {
+	return p.filter(ActionDrift)
+}
+
+// Unmanaged returns the resources that exist but are not in the spec.
+func (p Plan) Unmanaged() []Change {
+	return p.filter(ActionUnmanaged)
+}
+
+func (p Plan) filter(action Action) []Change {
+	var changes []Change
+	for _, change := range p.Changes {
+		if change.Action == action {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+/*
+Diff compares a spec with the platform and returns the plan to bring the
+platform in line. It only reads from the platform.
+*/
+func Diff(client Client, spec Spec) (Plan, error) {
+	customers, err := client.GetCustomers()
+	if err != nil {
+		return Plan{}, fmt.Errorf("list customers: %w", err)
+	}
+	live := make(map[string]tango.Customer, len(customers))
+	for _, customer := range customers {
+		live[customer.CustomerIdentifier] = customer
+	}
+
+	var plan Plan
+	managed := make(map[string]bool)
+	for _, desired := range spec.Customers {
+		managed[desired.Identifier] = true
+
+		customer, ok := live[desired.Identifier]
+		if !ok {
+			plan.add(Change{
+				Action:      ActionCreate,
+				Kind:        KindCustomer,
+				Customer:    desired.Identifier,
+				Detail:      fmt.Sprintf("display name %q", desired.DisplayName),
+				DisplayName: desired.DisplayName,
+			})
+			for _, account := range desired.Accounts {
+				plan.createAccount(desired.Identifier, account)
+			}
+			continue
+		}
+
+		if desired.DisplayName != "" && customer.DisplayName != desired.DisplayName {
+			plan.add(Change{
+				Action:   ActionDrift,
+				Kind:     KindCustomer,
+				Customer: desired.Identifier,
+				Detail:   fmt.Sprintf("display name is %q, spec has %q", customer.DisplayName, desired.DisplayName),
+			})
+		}
+
+		accounts := customer.Accounts
+		if len(accounts) == 0 {
+			accounts, err = client.GetCustomerAccounts(desired.Identifier)
+			if err != nil {
+				return Plan{}, fmt.Errorf("list accounts of customer %s: %w", desired.Identifier, err)
+			}
+		}
+		if err := plan.diffAccounts(client, desired, accounts); err != nil {
+			return Plan{}, err
+		}
+	}
+
+	for _, customer := range customers {
+		if !managed[customer.CustomerIdentifier] {
+			plan.add(Change{
+				Action:   ActionUnmanaged,
+				Kind:     KindCustomer,
+				Customer: customer.CustomerIdentifier,
+				Detail:   "not in the spec",
+			})
+		}
+	}
+
+	return plan, nil
+}
+
+func (p *Plan) diffAccounts(client Client, desired CustomerSpec, accounts []tango.UserAccount) error {
+	live := make(map[string]tango.UserAccount, len(accounts))
+	for _, account := range accounts {
+		live[account.AccountIdentifier] = account
+	}
+
+	managed := make(map[string]bool)
+	for _, want := range desired.Accounts {
+		managed[want.Identifier] = true
+
+		account, ok := live[want.Identifier]
+		if !ok {
+			p.createAccount(desired.Identifier, want)
+			continue
+		}
+
+		if want.DisplayName != "" && account.DisplayName != want.DisplayName {
+			p.add(Change{
+				Action:   ActionDrift,
+				Kind:     KindAccount,
+				Customer: desired.Identifier,
+				Account:  want.Identifier,
+				Detail:   fmt.Sprintf("display name is %q, spec has %q", account.DisplayName, want.DisplayName),
+			})
+		}
+
+		alerts, err := client.GetLowBalanceAlerts(want.Identifier)
+		if err != nil {
+			return fmt.Errorf("list low balance alerts of account %s: %w", want.Identifier, err)
+		}
+		p.diffAlerts(desired.Identifier, want, alerts)
+	}
+
+	for _, account := range accounts {
+		if !managed[account.AccountIdentifier] {
+			p.add(Change{
+				Action:   ActionUnmanaged,
+				Kind:     KindAccount,
+				Customer: desired.Identifier,
+				Account:  account.AccountIdentifier,
+				Detail:   "not in the spec",
+			})
+		}
+	}
+	return nil
+}
+
+// diffAlerts matches alerts by threshold, since an account cannot usefully
+// have two alerts at the same level.
+func (p *Plan) diffAlerts(customerID string, desired AccountSpec, alerts []tango.LowBalanceAlert) {
+	live := make(map[float64]tango.LowBalanceAlert, len(alerts))
+	for _, alert := range alerts {
+		live[alert.BalanceThreshold] = alert
+	}
+
+	managed := make(map[float64]bool)
+	for _, want := range desired.LowBalanceAlerts {
+		managed[want.Threshold] = true
+
+		alert, ok := live[want.Threshold]
+		if !ok {
+			p.createAlert(customerID, desired.Identifier, want)
+			continue
+		}
+		if !emails.Same(alert.EmailsToNotify, want.Emails) {
+			p.add(Change{
+				Action:   ActionDrift,
+				Kind:     KindLowBalanceAlert,
+				Customer: customerID,
+				Account:  desired.Identifier,
+				Detail: fmt.Sprintf("alert at %v notifies %s, spec has %s",
+					want.Threshold, strings.Join(alert.EmailsToNotify, ", "), strings.Join(want.Emails, ", ")),
+			})
+		}
+	}
+
+	for _, alert := range alerts {
+		if !managed[alert.BalanceThreshold] {
+			p.add(Change{
+				Action:   ActionUnmanaged,
+				Kind:     KindLowBalanceAlert,
+				Customer: customerID,
+				Account:  desired.Identifier,
+				Detail:   fmt.Sprintf("alert at %v is not in the spec", alert.BalanceThreshold),
+			})
+		}
+	}
+}
+
+func (p *Plan) createAccount(customerID string, account AccountSpec) {
+	p.add(Change{
+		Action:       ActionCreate,
+		Kind:         KindAccount,
+		Customer:     customerID,
+		Account:      account.Identifier,
+		Detail:       fmt.Sprintf("display name %q, contact %s", account.DisplayName, account.ContactEmail),
+		DisplayName:  account.DisplayName,
+		ContactEmail: account.ContactEmail,
+	})
+	for _, alert := range account.LowBalanceAlerts {
+		p.createAlert(customerID, account.Identifier, alert)
+	}
+}
+
+func (p *Plan) createAlert(customerID string, accountID string, alert AlertSpec) {
+	alert.Emails = append([]string(nil), alert.Emails...)
+	p.add(Change{
+		Action:   ActionCreate,
+		Kind:     KindLowBalanceAlert,
+		Customer: customerID,
+		Account:  accountID,
+		Detail:   fmt.Sprintf("alert at %v to %s", alert.Threshold, strings.Join(alert.Emails, ", ")),
+		Alert:    &alert,
+	})
+}
+
+func (p *Plan) add(change Change) {
+	p.Changes = append(p.Changes, change)
+}
+
+var actionSymbols = map[Action]string{
+	ActionCreate:    "+",
+	ActionDrift:     "~",
+	ActionUnmanaged: "?",
+}
+
+/*
+WriteText writes the plan for people to read, one change per line, followed
+by a summary:
+
+	~ customer acme: display name is "Acme", spec has "Acme Inc."
+	+ account acme/acme-sales: display name "Sales", contact sales@acme.example
+	~ lowBalanceAlert acme/acme-ops: alert at 500 notifies treasury@acme.example, spec has finance@acme.example
+	? account acme/acme-legacy: not in the spec
+
+	1 to create, 2 drifted, 1 unmanaged
+*/
+func (p Plan) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, change := range p.Changes {
+		b.WriteString(actionSymbols[change.Action])
+		b.WriteString(" ")
+		b.WriteString(string(change.Kind))
+		b.WriteString(" ")
+		b.WriteString(change.Path())
+		b.WriteString(": ")
+		b.WriteString(change.Detail)
+		b.WriteString("\n")
+	}
+	if len(p.Changes) > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "%d to create, %d drifted, %d unmanaged\n", len(p.Creates()), len(p.Drift()), len(p.Unmanaged()))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON writes the plan as indented JSON.
+func (p Plan) WriteJSON(w io.Writer) error {
+	if p.Changes == nil {
+		p.Changes = []Change{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(p)
+}
+
+// Path identifies the changed resource as customer or customer/account.
+func (c Change) Path() string {
+	if c.Account == "" {
+		return c.Customer
+	}
+	return c.Customer + "/" + c.Account
+}
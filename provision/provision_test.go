@@ -0,0 +1,242 @@
+package provision
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+type fakeClient struct {
+	customers []tango.Customer
+	accounts  map[string][]tango.UserAccount
+	alerts    map[string][]tango.LowBalanceAlert
+	failOn    string
+	calls     []string
+}
+
+func (f *fakeClient) GetCustomers() ([]tango.Customer, error) {
+	return f.customers, nil
+}
+
+func (f *fakeClient) GetCustomerAccounts(customerID string) ([]tango.UserAccount, error) {
+	return f.accounts[customerID], nil
+}
+
+func (f *fakeClient) GetLowBalanceAlerts(accountID string) ([]tango.LowBalanceAlert, error) {
+	return f.alerts[accountID], nil
+}
+
+func (f *fakeClient) EnsureCustomer(customerID string, displayName string) (tango.Customer, bool, error) {
+	f.calls = append(f.calls, "customer "+customerID)
+	if f.failOn == customerID {
+		return tango.Customer{}, false, errors.New("unavailable")
+	}
+	return tango.Customer{CustomerIdentifier: customerID, DisplayName: displayName}, true, nil
+}
+
+func (f *fakeClient) EnsureCustomerAccount(customerID string, accountID string, displayName string, contactEmail string) (tango.Account, bool, error) {
+	f.calls = append(f.calls, "account "+customerID+"/"+accountID)
+	if f.failOn == accountID {
+		return tango.Account{}, false, errors.New("unavailable")
+	}
+	return tango.Account{AccountIdentifier: accountID}, true, nil
+}
+
+func (f *fakeClient) CreateLowBalanceAlert(accountID string, data tango.LowBalanceAlertRequest) (tango.LowBalanceAlert, error) {
+	f.calls = append(f.calls, "alert "+accountID+" "+strings.Join(data.EmailsToNotify, ","))
+	return tango.LowBalanceAlert{BalanceThreshold: data.BalanceThreshold, EmailsToNotify: data.EmailsToNotify}, nil
+}
+
+const testSpec = `
+customers:
+  - identifier: acme
+    displayName: Acme Inc.
+    accounts:
+      - identifier: acme-ops
+        displayName: Operations
+        contactEmail: ops@acme.example
+        lowBalanceAlerts:
+          - threshold: 500
+            emails: [finance@acme.example]
+          - threshold: 100
+            emails: [cfo@acme.example]
+      - identifier: acme-sales
+        displayName: Sales
+        contactEmail: sales@acme.example
+  - identifier: globex
+    displayName: Globex
+    accounts:
+      - identifier: globex-main
+        displayName: Main
+        contactEmail: main@globex.example
+        lowBalanceAlerts:
+          - threshold: 250
+            emails: [ap@globex.example]
+`
+
+func liveClient() *fakeClient {
+	return &fakeClient{
+		customers: []tango.Customer{
+			{CustomerIdentifier: "acme", DisplayName: "Acme"},
+			{CustomerIdentifier: "initech", DisplayName: "Initech"},
+		},
+		accounts: map[string][]tango.UserAccount{
+			"acme": {
+				{AccountIdentifier: "acme-ops", DisplayName: "Operations"},
+				{AccountIdentifier: "acme-legacy", DisplayName: "Legacy"},
+			},
+		},
+		alerts: map[string][]tango.LowBalanceAlert{
+			"acme-ops": {
+				{BalanceThreshold: 500, EmailsToNotify: []string{"treasury@acme.example"}},
+				{BalanceThreshold: 50, EmailsToNotify: []string{"ops@acme.example"}},
+			},
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	spec, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if len(spec.Customers) != 2 || len(spec.Customers[0].Accounts) != 2 || spec.Customers[0].Accounts[0].LowBalanceAlerts[1].Threshold != 100 {
+		t.Fatalf("unexpected spec: %+v", spec)
+	}
+
+	// JSON is read the same way
+	asJSON, _ := json.Marshal(spec)
+	fromJSON, err := Parse(asJSON)
+	if err != nil || len(fromJSON.Customers) != 2 || fromJSON.Customers[1].Accounts[0].ContactEmail != "main@globex.example" {
+		t.Fatalf("unexpected spec from JSON: %+v, %v", fromJSON, err)
+	}
+
+	invalid := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{"unknown field", "customers:\n  - identifer: acme\n", "identifer"},
+		{"missing identifier", "customers:\n  - displayName: Acme\n", "identifier is required"},
+		{"duplicate customer", "customers:\n  - identifier: a\n  - identifier: a\n", "more than once"},
+		{"bad email", "customers:\n  - identifier: a\n    accounts:\n      - identifier: b\n        contactEmail: nope\n", "invalid contactEmail"},
+		{"alert without emails", "customers:\n  - identifier: a\n    accounts:\n      - identifier: b\n        contactEmail: b@example.com\n        lowBalanceAlerts:\n          - threshold: 10\n", "at least one email"},
+	}
+	for _, tt := range invalid {
+		if _, err := Parse([]byte(tt.spec)); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	spec, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	plan, err := Diff(liveClient(), spec)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+
+	var got []string
+	for _, change := range plan.Changes {
+		got = append(got, string(change.Action)+" "+string(change.Kind)+" "+change.Path())
+	}
+	want := []string{
+		"drift customer acme",
+		"drift lowBalanceAlert acme/acme-ops",
+		"create lowBalanceAlert acme/acme-ops",
+		"unmanaged lowBalanceAlert acme/acme-ops",
+		"create account acme/acme-sales",
+		"unmanaged account acme/acme-legacy",
+		"create customer globex",
+		"create account globex/globex-main",
+		"create lowBalanceAlert globex/globex-main",
+		"unmanaged customer initech",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected plan:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if len(plan.Creates()) != 5 || len(plan.Drift()) != 2 || len(plan.Unmanaged()) != 3 {
+		t.Fatalf("unexpected counts: %d, %d, %d", len(plan.Creates()), len(plan.Drift()), len(plan.Unmanaged()))
+	}
+}
+
+func TestPlanOutput(t *testing.T) {
+	spec, _ := Parse([]byte(testSpec))
+	plan, err := Diff(liveClient(), spec)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+
+	var text bytes.Buffer
+	if err := plan.WriteText(&text); err != nil {
+		t.Fatalf("write text: %v", err)
+	}
+	for _, line := range []string{
+		`~ customer acme: display name is "Acme", spec has "Acme Inc."`,
+		`+ account acme/acme-sales: display name "Sales", contact sales@acme.example`,
+		`? customer initech: not in the spec`,
+		`5 to create, 2 drifted, 3 unmanaged`,
+	} {
+		if !strings.Contains(text.String(), line+"\n") {
+			t.Errorf("expected text output to contain %q, got:\n%s", line, text.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := plan.WriteJSON(&buf); err != nil {
+		t.Fatalf("write JSON: %v", err)
+	}
+	var decoded Plan
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode JSON: %v", err)
+	}
+	if len(decoded.Changes) != len(plan.Changes) || decoded.Changes[2].Alert == nil || decoded.Changes[2].Alert.Threshold != 100 {
+		t.Fatalf("unexpected JSON plan: %s", buf.String())
+	}
+
+	buf.Reset()
+	_ = Plan{}.WriteJSON(&buf)
+	if !strings.Contains(buf.String(), `"changes": []`) {
+		t.Fatalf("expected an empty plan to have an empty list, got %s", buf.String())
+	}
+}
+
+func TestApply(t *testing.T) {
+	spec, _ := Parse([]byte(testSpec))
+	client := liveClient()
+	plan, err := Diff(client, spec)
+	if err != nil {
+		t.Fatalf("diff: %v", err)
+	}
+
+	applied, err := Apply(client, plan)
+	if err != nil || len(applied) != 5 {
+		t.Fatalf("apply: %d applied, %v", len(applied), err)
+	}
+	want := []string{
+		"alert acme-ops cfo@acme.example",
+		"account acme/acme-sales",
+		"customer globex",
+		"account globex/globex-main",
+		"alert globex-main ap@globex.example",
+	}
+	if strings.Join(client.calls, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected calls:\n%s", strings.Join(client.calls, "\n"))
+	}
+
+	// A failure stops the run and reports what was done before it
+	client = liveClient()
+	client.failOn = "globex"
+	applied, err = Apply(client, plan)
+	if err == nil || !strings.Contains(err.Error(), "create customer globex") || len(applied) != 2 {
+		t.Fatalf("expected to stop at globex after 2 creates, got %d, %v", len(applied), err)
+	}
+}
@@ -0,0 +1,143 @@
+// Package provision keeps the customers, accounts and low balance alerts on a
+// Tango platform in line with a spec kept in version control.
+//
+// A spec is read from YAML or JSON, compared with the live platform to build a
+// Plan, and the plan's creates are applied. Nothing is ever deleted or
+// changed: differences that could only be resolved that way are reported as
+// drift, and live resources missing from the spec as unmanaged.
+package provision
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/c150pilot/go-tango-card/internal/emails"
+)
+
+/*
+Spec is the desired state of a platform, for example:
+
+	customers:
+	  - identifier: acme
+	    displayName: Acme Inc.
+	    accounts:
+	      - identifier: acme-ops
+	        displayName: Operations
+	        contactEmail: ops@acme.example
+	        lowBalanceAlerts:
+	          - threshold: 500
+	            emails: [finance@acme.example]
+
+The same structure can be written as JSON.
+*/
+type Spec struct {
+	Customers []CustomerSpec `yaml:"customers" json:"customers"`
+}
+
+type CustomerSpec struct {
+	Identifier  string        `yaml:"identifier" json:"identifier"`
+	DisplayName string        `yaml:"displayName" json:"displayName"`
+	Accounts    []AccountSpec `yaml:"accounts" json:"accounts"`
+}
+
+type AccountSpec struct {
+	Identifier       string      `yaml:"identifier" json:"identifier"`
+	DisplayName      string      `yaml:"displayName" json:"displayName"`
+	ContactEmail     string      `yaml:"contactEmail" json:"contactEmail"`
+	LowBalanceAlerts []AlertSpec `yaml:"lowBalanceAlerts" json:"lowBalanceAlerts"`
+}
+
+type AlertSpec struct {
+	Threshold float64  `yaml:"threshold" json:"threshold"`
+	Emails    []string `yaml:"emails" json:"emails"`
+}
+
+// Load reads a spec from a YAML or JSON file.
+func Load(path string) (Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Spec{}, err
+	}
+	spec, err := Parse(data)
+	if err != nil {
+		return Spec{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// Parse reads a spec from YAML or JSON. Unknown fields are rejected so a
+// misspelled key is not silently ignored, and the spec is validated.
+func Parse(data []byte) (Spec, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var spec Spec
+	if err := decoder.Decode(&spec); err != nil && !errors.Is(err, io.EOF) {
+		return Spec{}, err
+	}
+	if err := spec.Validate(); err != nil {
+		return Spec{}, err
+	}
+	return spec, nil
+}
+
+// Validate checks that every identifier is present and unique and every
+// email address is valid.
+func (s Spec) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	customers := make(map[string]bool)
+	accounts := make(map[string]bool)
+	for i, customer := range s.Customers {
+		switch {
+		case customer.Identifier == "":
+			fail("customers[%d]: identifier is required", i)
+		case customers[customer.Identifier]:
+			fail("customer %q is listed more than once", customer.Identifier)
+		}
+		customers[customer.Identifier] = true
+
+		for j, account := range customer.Accounts {
+			switch {
+			case account.Identifier == "":
+				fail("customer %q: accounts[%d]: identifier is required", customer.Identifier, j)
+			case accounts[account.Identifier]:
+				fail("account %q is listed more than once", account.Identifier)
+			}
+			accounts[account.Identifier] = true
+
+			if !emails.Valid(account.ContactEmail) {
+				fail("account %q: invalid contactEmail %q", account.Identifier, account.ContactEmail)
+			}
+
+			thresholds := make(map[float64]bool)
+			for _, alert := range account.LowBalanceAlerts {
+				if alert.Threshold <= 0 {
+					fail("account %q: alert threshold must be greater than zero", account.Identifier)
+				}
+				if thresholds[alert.Threshold] {
+					fail("account %q: alert threshold %v is listed more than once", account.Identifier, alert.Threshold)
+				}
+				thresholds[alert.Threshold] = true
+				if len(alert.Emails) == 0 {
+					fail("account %q: alert at %v needs at least one email", account.Identifier, alert.Threshold)
+				}
+				for _, email := range alert.Emails {
+					if !emails.Valid(email) {
+						fail("account %q: alert at %v: invalid email %q", account.Identifier, alert.Threshold, email)
+					}
+				}
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,401 @@
+// Package scheduler holds Tango orders until a chosen time and then places
+// them, for example a birthday reward sent at 9am in the recipient's own time
+// zone.
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	tango "github.com/c150pilot/go-tango-card"
+	"github.com/c150pilot/go-tango-card/internal/ids"
+	"github.com/c150pilot/go-tango-card/internal/retry"
+)
+
+const (
+	// DefaultPollInterval is how often Run checks for due deliveries.
+	DefaultPollInterval = 30 * time.Second
+	// DefaultMaxAttempts is how many times a delivery's order is tried before
+	// it is marked failed.
+	DefaultMaxAttempts = 8
+)
+
+// State is where a delivery is in its life.
+type State string
+
+const (
+	// StateScheduled deliveries are waiting for their send time.
+	StateScheduled State = "scheduled"
+	// StateSending deliveries have been picked up and their order is being
+	// placed. A delivery left in this state by a crash is not fired again; check
+	// it by its ExternalRefID before rescheduling it.
+	StateSending State = "sending"
+	// StateSent deliveries have been ordered.
+	StateSent State = "sent"
+	// StateFailed deliveries were rejected when their order was placed, or
+	// kept failing for MaxAttempts attempts.
+	StateFailed State = "failed"
+	// StateCancelled deliveries were cancelled before they were sent.
+	StateCancelled State = "cancelled"
+)
+
+var (
+	// ErrNotFound is returned for an unknown delivery ID.
+	ErrNotFound = errors.New("scheduler: delivery not found")
+	// ErrNotScheduled is returned when cancelling or rescheduling a delivery
+	// that is no longer waiting to be sent.
+	ErrNotScheduled = errors.New("scheduler: delivery is not scheduled")
+)
+
+// Delivery is an order waiting for, or sent at, its scheduled time.
+type Delivery struct {
+	ID    string                `json:"id"`
+	Order tango.CreateOrderData `json:"order"`
+	State State                 `json:"state"`
+
+	// SendAt is the instant the order is due.
+	SendAt time.Time `json:"sendAt"`
+	// TimeZone is the IANA time zone the send time was given in, such as
+	// "America/New_York". Empty if SendAt was given as an absolute instant.
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// Attempts is how many times the order has been tried.
+	Attempts         int       `json:"attempts,omitempty"`
+	ReferenceOrderID string    `json:"referenceOrderID,omitempty"`
+	LastError        string    `json:"lastError,omitempty"`
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// LocalSendAt returns the send time in the delivery's time zone.
+func (d Delivery) LocalSendAt() time.Time {
+	if d.TimeZone == "" {
+		return d.SendAt
+	}
+	loc, err := time.LoadLocation(d.TimeZone)
+	if err != nil {
+		return d.SendAt
+	}
+	return d.SendAt.In(loc)
+}
+
+// Store persists deliveries. Put inserts or replaces a delivery by ID.
+type Store interface {
+	Put(delivery Delivery) error
+	Get(id string) (Delivery, error)
+	List() ([]Delivery, error)
+}
+
+// Clock tells the scheduler the time. Tests substitute a fixed one.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Orderer is implemented by *tango.TangoClient.
+type Orderer interface {
+	Order(data tango.CreateOrderData) (tango.CreateOrderResponse, error)
+	FindOrdersByExternalRefID(externalRefID string) ([]tango.CreateOrderResponse, error)
+}
+
+// Scheduler places scheduled orders once they are due.
+type Scheduler struct {
+	Store  Store
+	Client Orderer
+
+	// Clock is the scheduler's time source. Nil uses the system clock.
+	Clock Clock
+	// PollInterval is how often Run checks for due deliveries. Zero uses
+	// DefaultPollInterval.
+	PollInterval time.Duration
+	// MaxAttempts is how many times a delivery's order is tried before it is
+	// marked failed. Zero uses DefaultMaxAttempts.
+	MaxAttempts int
+	// Backoff returns how long to wait before the next try after a failed
+	// attempt (1 for the first). Nil waits 10 seconds and doubles with each
+	// attempt, up to 30 minutes.
+	Backoff func(attempt int) time.Duration
+
+	// mu keeps Cancel and Reschedule from racing a delivery being fired.
+	mu sync.Mutex
+}
+
+/*
+Schedule stores order to be placed at sendAt in timeZone. The wall-clock
+reading of sendAt (year through nanosecond) is read in timeZone, so
+
+	s.Schedule(order, time.Date(2025, 3, 14, 9, 0, 0, 0, time.UTC), "America/Denver")
+
+sends at 9am Denver time whatever location sendAt carries, on either side of a
+daylight saving change. A time that falls in a daylight saving gap or overlap
+resolves to one of the two instants it could mean. With an empty timeZone,
+sendAt is used as the absolute instant it represents.
+
+An order without an externalRefID is given the delivery ID.
+*/
+func (s *Scheduler) Schedule(order tango.CreateOrderData, sendAt time.Time, timeZone string) (Delivery, error) {
+	at, err := resolveSendAt(sendAt, timeZone)
+	if err != nil {
+		return Delivery{}, err
+	}
+	id, err := ids.New()
+	if err != nil {
+		return Delivery{}, err
+	}
+	if order.ExternalRefID == "" {
+		order.ExternalRefID = id
+	}
+
+	now := s.now()
+	delivery := Delivery{
+		ID:        id,
+		Order:     order,
+		State:     StateScheduled,
+		SendAt:    at,
+		TimeZone:  timeZone,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.Store.Put(delivery); err != nil {
+		return Delivery{}, err
+	}
+	return delivery, nil
+}
+
+// Cancel stops a scheduled delivery from being sent.
+func (s *Scheduler) Cancel(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivery, err := s.scheduled(id)
+	if err != nil {
+		return err
+	}
+	delivery.State = StateCancelled
+	delivery.UpdatedAt = s.now()
+	return s.Store.Put(delivery)
+}
+
+// Reschedule moves a scheduled delivery to a new send time, read the same way
+// as in Schedule. An empty timeZone keeps the delivery's current time zone.
+func (s *Scheduler) Reschedule(id string, sendAt time.Time, timeZone string) (Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivery, err := s.scheduled(id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	if timeZone == "" {
+		timeZone = delivery.TimeZone
+	}
+	at, err := resolveSendAt(sendAt, timeZone)
+	if err != nil {
+		return Delivery{}, err
+	}
+
+	delivery.SendAt = at
+	delivery.TimeZone = timeZone
+	delivery.UpdatedAt = s.now()
+	if err := s.Store.Put(delivery); err != nil {
+		return Delivery{}, err
+	}
+	return delivery, nil
+}
+
+// Pending returns the deliveries still waiting to be sent, soonest first.
+func (s *Scheduler) Pending() ([]Delivery, error) {
+	deliveries, err := s.Store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Delivery
+	for _, delivery := range deliveries {
+		if delivery.State == StateScheduled {
+			pending = append(pending, delivery)
+		}
+	}
+	sortBySendAt(pending)
+	return pending, nil
+}
+
+/*
+RunDue places every order that is due, soonest first, and returns how many it
+attempted. A delivery is marked StateSending before its order is placed and
+StateSent or StateFailed afterwards; a rejected order does not stop the rest.
+
+An order that fails for a reason that may pass, such as rate limiting, a
+server error or a network failure, is put back to StateScheduled with SendAt
+moved on by Backoff. Before trying again the scheduler looks the order up by
+its externalRefID, in case the earlier attempt went through.
+
+An error is returned only if the store fails.
+*/
+func (s *Scheduler) RunDue() (int, error) {
+	pending, err := s.Pending()
+	if err != nil {
+		return 0, err
+	}
+
+	fired := 0
+	for _, delivery := range pending {
+		if delivery.SendAt.After(s.now()) {
+			break
+		}
+		ok, err := s.fire(delivery.ID)
+		if err != nil {
+			return fired, err
+		}
+		if ok {
+			fired++
+		}
+	}
+	return fired, nil
+}
+
+// Run calls RunDue every PollInterval until ctx is cancelled, then returns nil.
+// A pass that is placing orders when ctx is cancelled is finished first.
+func (s *Scheduler) Run(ctx context.Context) error {
+	interval := s.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+
+	for {
+		if _, err := s.RunDue(); err != nil {
+			return err
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+	}
+}
+
+// fire places a single delivery's order. It reports false if the delivery
+// was cancelled or rescheduled in the meantime.
+func (s *Scheduler) fire(id string) (bool, error) {
+	s.mu.Lock()
+	delivery, err := s.Store.Get(id)
+	if err != nil || delivery.State != StateScheduled || delivery.SendAt.After(s.now()) {
+		s.mu.Unlock()
+		return false, err
+	}
+	delivery.State = StateSending
+	delivery.Attempts++
+	delivery.UpdatedAt = s.now()
+	err = s.Store.Put(delivery)
+	s.mu.Unlock()
+	if err != nil {
+		return false, err
+	}
+
+	referenceOrderID, err := s.place(delivery)
+	if err != nil {
+		s.failed(&delivery, err)
+	} else {
+		delivery.State = StateSent
+		delivery.ReferenceOrderID = referenceOrderID
+		delivery.LastError = ""
+	}
+	delivery.UpdatedAt = s.now()
+	return true, s.Store.Put(delivery)
+}
+
+// place orders a delivery and returns the reference order ID. A retry first
+// looks for an order placed by an earlier attempt that failed in transit.
+func (s *Scheduler) place(delivery Delivery) (string, error) {
+	if delivery.Attempts > 1 {
+		orders, err := s.Client.FindOrdersByExternalRefID(delivery.Order.ExternalRefID)
+		if err != nil {
+			return "", fmt.Errorf("looking up earlier attempt: %w", err)
+		}
+		if len(orders) > 0 {
+			return orders[0].ReferenceOrderID, nil
+		}
+	}
+
+	resp, err := s.Client.Order(delivery.Order)
+	if err != nil {
+		return "", err
+	}
+	return resp.ReferenceOrderID, nil
+}
+
+// failed records a failed attempt, rescheduling the delivery if err may pass.
+func (s *Scheduler) failed(delivery *Delivery, err error) {
+	maxAttempts := s.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	delivery.State = StateFailed
+	delivery.LastError = err.Error()
+	switch {
+	case !retry.Retryable(err):
+	case delivery.Attempts >= maxAttempts:
+		delivery.LastError = fmt.Sprintf("giving up after %d attempts: %v", delivery.Attempts, err)
+	default:
+		backoff := s.Backoff
+		if backoff == nil {
+			backoff = retry.Backoff
+		}
+		delivery.State = StateScheduled
+		delivery.SendAt = s.now().Add(backoff(delivery.Attempts))
+	}
+}
+
+func (s *Scheduler) scheduled(id string) (Delivery, error) {
+	delivery, err := s.Store.Get(id)
+	if err != nil {
+		return Delivery{}, err
+	}
+	if delivery.State != StateScheduled {
+		return Delivery{}, fmt.Errorf("%w: %s is %s", ErrNotScheduled, id, delivery.State)
+	}
+	return delivery, nil
+}
+
+func (s *Scheduler) now() time.Time {
+	if s.Clock == nil {
+		return systemClock{}.Now()
+	}
+	return s.Clock.Now()
+}
+
+func resolveSendAt(sendAt time.Time, timeZone string) (time.Time, error) {
+	if sendAt.IsZero() {
+		return time.Time{}, fmt.Errorf("send time is required")
+	}
+	if timeZone == "" {
+		return sendAt.UTC(), nil
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time zone %q: %w", timeZone, err)
+	}
+	local := time.Date(sendAt.Year(), sendAt.Month(), sendAt.Day(),
+		sendAt.Hour(), sendAt.Minute(), sendAt.Second(), sendAt.Nanosecond(), loc)
+	return local.UTC(), nil
+}
+
+func sortBySendAt(deliveries []Delivery) {
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		if !deliveries[i].SendAt.Equal(deliveries[j].SendAt) {
+			return deliveries[i].SendAt.Before(deliveries[j].SendAt)
+		}
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+}
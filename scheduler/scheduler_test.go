@@ -0,0 +1,241 @@
+package scheduler
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	tango "github.com/c150pilot/go-tango-card"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.t }
+
+type fakeOrderer struct {
+	orders  []tango.CreateOrderData
+	err     error
+	lookups []string
+	found   []tango.CreateOrderResponse
+}
+
+func (f *fakeOrderer) Order(data tango.CreateOrderData) (tango.CreateOrderResponse, error) {
+	f.orders = append(f.orders, data)
+	if f.err != nil {
+		return tango.CreateOrderResponse{}, f.err
+	}
+	return tango.CreateOrderResponse{ReferenceOrderID: "RA-" + data.ExternalRefID}, nil
+}
+
+func (f *fakeOrderer) FindOrdersByExternalRefID(externalRefID string) ([]tango.CreateOrderResponse, error) {
+	f.lookups = append(f.lookups, externalRefID)
+	return f.found, nil
+}
+
+func newTestScheduler(start time.Time) (*Scheduler, *fakeClock, *fakeOrderer) {
+	clock := &fakeClock{t: start}
+	client := &fakeOrderer{}
+	return &Scheduler{Store: NewMemoryStore(), Client: client, Clock: clock}, clock, client
+}
+
+func runDue(t *testing.T, s *Scheduler) int {
+	t.Helper()
+	n, err := s.RunDue()
+	if err != nil {
+		t.Fatalf("run due: %v", err)
+	}
+	return n
+}
+
+func TestSchedule_LocalTimeAcrossDST(t *testing.T) {
+	s, _, _ := newTestScheduler(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	// 9am in New York is 14:00 UTC before the March change and 13:00 after
+	before, err := s.Schedule(tango.CreateOrderData{}, time.Date(2024, 3, 8, 9, 0, 0, 0, time.UTC), "America/New_York")
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	after, err := s.Schedule(tango.CreateOrderData{}, time.Date(2024, 3, 11, 9, 0, 0, 0, time.UTC), "America/New_York")
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+
+	if want := time.Date(2024, 3, 8, 14, 0, 0, 0, time.UTC); !before.SendAt.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, before.SendAt)
+	}
+	if want := time.Date(2024, 3, 11, 13, 0, 0, 0, time.UTC); !after.SendAt.Equal(want) {
+		t.Fatalf("expected %v, got %v", want, after.SendAt)
+	}
+	if got := after.LocalSendAt(); got.Hour() != 9 || got.Location().String() != "America/New_York" {
+		t.Fatalf("unexpected local send time %v", got)
+	}
+
+	if _, err := s.Schedule(tango.CreateOrderData{}, time.Now(), "Mars/Olympus_Mons"); err == nil {
+		t.Fatal("expected an error for an unknown time zone")
+	}
+}
+
+func TestRunDue_FiresWhenDue(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s, clock, client := newTestScheduler(start)
+
+	later, _ := s.Schedule(tango.CreateOrderData{ExternalRefID: "later"}, start.Add(2*time.Hour), "")
+	soon, _ := s.Schedule(tango.CreateOrderData{ExternalRefID: "soon"}, start.Add(time.Hour), "")
+
+	if n := runDue(t, s); n != 0 {
+		t.Fatalf("expected nothing due yet, fired %d", n)
+	}
+
+	clock.t = start.Add(time.Hour)
+	if n := runDue(t, s); n != 1 {
+		t.Fatalf("expected one delivery, fired %d", n)
+	}
+	got, _ := s.Store.Get(soon.ID)
+	if got.State != StateSent || got.ReferenceOrderID != "RA-soon" {
+		t.Fatalf("unexpected delivery: %+v", got)
+	}
+
+	pending, _ := s.Pending()
+	if len(pending) != 1 || pending[0].ID != later.ID {
+		t.Fatalf("expected only the later delivery to be pending, got %+v", pending)
+	}
+
+	clock.t = start.Add(3 * time.Hour)
+	runDue(t, s)
+	if len(client.orders) != 2 || client.orders[1].ExternalRefID != "later" {
+		t.Fatalf("unexpected orders: %+v", client.orders)
+	}
+}
+
+func TestRunDue_RecordsFailure(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s, _, client := newTestScheduler(start)
+	client.err = errors.New("insufficient funds")
+
+	delivery, _ := s.Schedule(tango.CreateOrderData{}, start, "")
+	runDue(t, s)
+
+	got, _ := s.Store.Get(delivery.ID)
+	if got.State != StateFailed || got.LastError != "insufficient funds" {
+		t.Fatalf("unexpected delivery: %+v", got)
+	}
+	if client.orders[0].ExternalRefID != delivery.ID {
+		t.Fatalf("expected externalRefID to default to the delivery ID, got %q", client.orders[0].ExternalRefID)
+	}
+}
+
+func TestRunDue_RetriesTemporaryFailure(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s, clock, client := newTestScheduler(start)
+	s.Backoff = func(attempt int) time.Duration { return time.Duration(attempt) * time.Minute }
+	client.err = &tango.APIError{StatusCode: 503}
+
+	delivery, _ := s.Schedule(tango.CreateOrderData{}, start, "")
+	runDue(t, s)
+
+	got, _ := s.Store.Get(delivery.ID)
+	if got.State != StateScheduled || got.Attempts != 1 || !got.SendAt.Equal(start.Add(time.Minute)) || got.LastError == "" {
+		t.Fatalf("expected the delivery to be rescheduled, got %+v", got)
+	}
+	if pending, _ := s.Pending(); len(pending) != 1 {
+		t.Fatalf("expected the delivery to stay pending, got %+v", pending)
+	}
+
+	// The first attempt went through after all, so it is found instead of ordered again
+	client.err = nil
+	client.found = []tango.CreateOrderResponse{{ReferenceOrderID: "RA-first"}}
+	clock.t = start.Add(time.Minute)
+	runDue(t, s)
+
+	got, _ = s.Store.Get(delivery.ID)
+	if got.State != StateSent || got.ReferenceOrderID != "RA-first" || got.LastError != "" {
+		t.Fatalf("expected the earlier order to be recorded, got %+v", got)
+	}
+	if len(client.orders) != 1 || len(client.lookups) != 1 || client.lookups[0] != delivery.ID {
+		t.Fatalf("expected one order and one lookup, got %d orders and lookups %v", len(client.orders), client.lookups)
+	}
+}
+
+func TestRunDue_GivesUpAfterMaxAttempts(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s, clock, client := newTestScheduler(start)
+	s.MaxAttempts = 2
+	client.err = &tango.APIError{StatusCode: 503}
+
+	delivery, _ := s.Schedule(tango.CreateOrderData{}, start, "")
+	runDue(t, s)
+	clock.t = start.Add(time.Hour)
+	runDue(t, s)
+
+	got, _ := s.Store.Get(delivery.ID)
+	if got.State != StateFailed || got.Attempts != 2 || !strings.HasPrefix(got.LastError, "giving up after 2 attempts") {
+		t.Fatalf("expected the delivery to fail after two attempts, got %+v", got)
+	}
+}
+
+func TestCancelAndReschedule(t *testing.T) {
+	start := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	s, clock, client := newTestScheduler(start)
+
+	cancelled, _ := s.Schedule(tango.CreateOrderData{ExternalRefID: "cancelled"}, start.Add(time.Hour), "")
+	moved, _ := s.Schedule(tango.CreateOrderData{ExternalRefID: "moved"}, time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC), "Europe/London")
+
+	if err := s.Cancel(cancelled.ID); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	if err := s.Cancel(cancelled.ID); !errors.Is(err, ErrNotScheduled) {
+		t.Fatalf("expected ErrNotScheduled, got %v", err)
+	}
+	if err := s.Cancel("missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	// The time zone is kept when none is given
+	rescheduled, err := s.Reschedule(moved.ID, time.Date(2024, 6, 2, 9, 0, 0, 0, time.UTC), "")
+	if err != nil {
+		t.Fatalf("reschedule: %v", err)
+	}
+	if want := time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC); !rescheduled.SendAt.Equal(want) || rescheduled.TimeZone != "Europe/London" {
+		t.Fatalf("unexpected rescheduled delivery: %+v", rescheduled)
+	}
+
+	clock.t = time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC)
+	if n := runDue(t, s); n != 0 {
+		t.Fatalf("expected nothing due, fired %d", n)
+	}
+
+	clock.t = time.Date(2024, 6, 2, 8, 0, 0, 0, time.UTC)
+	runDue(t, s)
+	if len(client.orders) != 1 || client.orders[0].ExternalRefID != "moved" {
+		t.Fatalf("unexpected orders: %+v", client.orders)
+	}
+	if _, err := s.Reschedule(moved.ID, clock.t.Add(time.Hour), ""); !errors.Is(err, ErrNotScheduled) {
+		t.Fatalf("expected sent delivery to be fixed, got %v", err)
+	}
+}
+
+func TestFileStore_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "schedule.json")
+	store, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	s := &Scheduler{Store: store, Client: &fakeOrderer{}}
+	delivery, err := s.Schedule(tango.CreateOrderData{Utid: "U1"}, time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC), "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+
+	reopened, err := OpenFileStore(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	got, err := reopened.Get(delivery.ID)
+	if err != nil || !got.SendAt.Equal(delivery.SendAt) || got.TimeZone != "Asia/Tokyo" || got.Order.Utid != "U1" {
+		t.Fatalf("unexpected delivery after reopen: %+v, %v", got, err)
+	}
+}
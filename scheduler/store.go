@@ -0,0 +1,118 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/c150pilot/go-tango-card/internal/jsonfile"
+)
+
+// MemoryStore is a Store that keeps deliveries in memory only.
+type MemoryStore struct {
+	mu         sync.Mutex
+	deliveries map[string]Delivery
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{deliveries: make(map[string]Delivery)}
+}
+
+func (s *MemoryStore) Put(delivery Delivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deliveries[delivery.ID] = delivery
+	return nil
+}
+
+func (s *MemoryStore) Get(id string) (Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivery, ok := s.deliveries[id]
+	if !ok {
+		return Delivery{}, ErrNotFound
+	}
+	return delivery, nil
+}
+
+func (s *MemoryStore) List() ([]Delivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deliveries := make([]Delivery, 0, len(s.deliveries))
+	for _, delivery := range s.deliveries {
+		deliveries = append(deliveries, delivery)
+	}
+	sortBySendAt(deliveries)
+	return deliveries, nil
+}
+
+// FileStore is a Store kept in a single JSON file, rewritten through a
+// temporary file and a rename on every change.
+type FileStore struct {
+	path   string
+	memory *MemoryStore
+}
+
+// OpenFileStore opens the deliveries stored at path, creating the file on the
+// first change if it does not exist.
+func OpenFileStore(path string) (*FileStore, error) {
+	s := &FileStore{path: path, memory: NewMemoryStore()}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []Delivery
+	if err := json.Unmarshal(data, &deliveries); err != nil {
+		return nil, fmt.Errorf("scheduler file %s is corrupt: %w", path, err)
+	}
+	for _, delivery := range deliveries {
+		s.memory.deliveries[delivery.ID] = delivery
+	}
+	return s, nil
+}
+
+func (s *FileStore) Put(delivery Delivery) error {
+	s.memory.mu.Lock()
+	defer s.memory.mu.Unlock()
+
+	previous, existed := s.memory.deliveries[delivery.ID]
+	s.memory.deliveries[delivery.ID] = delivery
+	if err := s.save(); err != nil {
+		if existed {
+			s.memory.deliveries[delivery.ID] = previous
+		} else {
+			delete(s.memory.deliveries, delivery.ID)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FileStore) Get(id string) (Delivery, error) {
+	return s.memory.Get(id)
+}
+
+func (s *FileStore) List() ([]Delivery, error) {
+	return s.memory.List()
+}
+
+// save writes every delivery to the file. The caller must hold s.memory.mu.
+func (s *FileStore) save() error {
+	deliveries := make([]Delivery, 0, len(s.memory.deliveries))
+	for _, delivery := range s.memory.deliveries {
+		deliveries = append(deliveries, delivery)
+	}
+	sortBySendAt(deliveries)
+	return jsonfile.Write(s.path, deliveries)
+}
@@ -1,16 +1,91 @@
 package tango
 
-import "fmt"
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
 
 var ApiURL = "https://integration-api.tangocard.com/raas/v2"
 
+const (
+	productionURL = "https://api.tangocard.com/raas/v2"
+	sandboxURL    = "https://integration-api.tangocard.com/raas/v2"
+)
+
 var TangoClientInstance *TangoClient
 
+// timeNow is replaced in tests.
+var timeNow = time.Now
+
 type TangoClient struct {
 	Environment       string
 	Token             string
 	SendEmail         bool
 	AccountIdentifier string
+
+	// BaseURL, when set, is used instead of the package-level ApiURL so
+	// clients for different environments can be used side by side.
+	BaseURL string
+
+	// TokenSource, when set, supplies the bearer token for each request in
+	// place of Token, so the token can be refreshed while the client is in use.
+	TokenSource TokenSource
+
+	// Journal, when set, records every Order attempt so it can be reconciled
+	// with Recover after a crash.
+	Journal Journal
+
+	// CheckEmailTemplates makes Order confirm that an order's Etid exists
+	// before submitting it, failing with ErrUnknownEmailTemplate if not.
+	CheckEmailTemplates bool
+
+	// TransferStore, when set, keeps the records behind
+	// TransferFundsRequest.TransferID. When nil they are kept in the client's
+	// memory and lost when the process restarts.
+	TransferStore TransferStore
+
+	// TransferRetention is how long a completed transfer's TransferID is
+	// remembered. Zero uses DefaultTransferRetention.
+	TransferRetention time.Duration
+
+	// ResendCooldown is the minimum time between resends of the same line item
+	// through ResendLineItemWithOptions. Zero uses DefaultResendCooldown; a
+	// negative value disables the check.
+	ResendCooldown time.Duration
+
+	// shared holds the client's in-memory bookkeeping. It is a pointer so the
+	// client can be copied; copies made after first use share it.
+	shared *clientState
+}
+
+// clientState is the in-memory bookkeeping of a TangoClient: resend times,
+// known email templates and, without a TransferStore, transfer IDs. It lives
+// only as long as the client.
+type clientState struct {
+	resendMu    sync.Mutex
+	lastResends map[string]time.Time
+
+	templatesMu    sync.Mutex
+	knownTemplates map[string]bool
+
+	transfers *MemoryTransferStore
+}
+
+// clientStateMu guards the lazy creation of TangoClient.shared, so clients
+// built as struct literals need no constructor.
+var clientStateMu sync.Mutex
+
+func (c *TangoClient) state() *clientState {
+	clientStateMu.Lock()
+	defer clientStateMu.Unlock()
+
+	if c.shared == nil {
+		c.shared = &clientState{transfers: NewMemoryTransferStore()}
+	}
+	return c.shared
 }
 
 func New(token string, accountIdentifier string, sendEmail bool, env string) (*TangoClient, error) {
@@ -28,11 +103,7 @@ func New(token string, accountIdentifier string, sendEmail bool, env string) (*T
 	}
 
 	// Set Proper API URL
-	if env == "production" {
-		ApiURL = "https://api.tangocard.com/raas/v2"
-	} else {
-		ApiURL = "https://integration-api.tangocard.com/raas/v2"
-	}
+	ApiURL = environmentURL(env)
 
 	// Return new Client
 	TangoClientInstance = &TangoClient{
@@ -44,3 +115,36 @@ func New(token string, accountIdentifier string, sendEmail bool, env string) (*T
 
 	return TangoClientInstance, nil
 }
+
+func environmentURL(env string) string {
+	if env == "production" {
+		return productionURL
+	}
+	return sandboxURL
+}
+
+func (c *TangoClient) apiURL() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return ApiURL
+}
+
+// newHTTPClient returns the resty client for one request, with the bearer
+// token set: from the TokenSource when there is one, otherwise Token.
+func (c *TangoClient) newHTTPClient() *resty.Client {
+	client := resty.New()
+	if c.TokenSource == nil {
+		client.SetHeader("Authorization", "Bearer "+c.Token)
+		return client
+	}
+	client.OnBeforeRequest(func(_ *resty.Client, r *resty.Request) error {
+		token, err := c.TokenSource.Token()
+		if err != nil {
+			return fmt.Errorf("get token: %w", err)
+		}
+		r.SetHeader("Authorization", "Bearer "+token)
+		return nil
+	})
+	return client
+}
@@ -0,0 +1,75 @@
+package tango
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTokenRefreshLeeway is how long before expiry a RefreshingTokenSource
+// fetches a new token when no Leeway is set.
+const DefaultTokenRefreshLeeway = 5 * time.Minute
+
+// defaultTokenLifetime is assumed for a token response without expires_in.
+const defaultTokenLifetime = time.Hour
+
+// TokenSource supplies bearer tokens to a TangoClient.
+type TokenSource interface {
+	Token() (string, error)
+}
+
+/*
+RefreshingTokenSource fetches an OAuth token with GetTokenWithServiceAccount
+and fetches a new one shortly before it expires. It is safe for concurrent
+use: callers that need a new token at the same time wait for a single fetch.
+
+If a refresh fails while the current token has not yet expired, the current
+token is returned and the refresh is tried again on the next call.
+*/
+type RefreshingTokenSource struct {
+	ClientID               string
+	ClientSecret           string
+	ServiceAccountUsername string
+	ServiceAccountPassword string
+	Environment            string
+
+	// Leeway is how long before expiry the token is refreshed. Zero uses
+	// DefaultTokenRefreshLeeway.
+	Leeway time.Duration
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func (s *RefreshingTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := timeNow()
+	if s.token != "" && now.Add(s.leeway()).Before(s.expires) {
+		return s.token, nil
+	}
+
+	response, _, err := GetTokenWithServiceAccount(s.ClientID, s.ClientSecret, s.ServiceAccountUsername, s.ServiceAccountPassword, s.Environment)
+	if err != nil {
+		if s.token != "" && now.Before(s.expires) {
+			return s.token, nil
+		}
+		return "", err
+	}
+
+	lifetime := time.Duration(response.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = defaultTokenLifetime
+	}
+	s.token = response.AccessToken
+	s.expires = now.Add(lifetime)
+	return s.token, nil
+}
+
+func (s *RefreshingTokenSource) leeway() time.Duration {
+	if s.Leeway > 0 {
+		return s.Leeway
+	}
+	return DefaultTokenRefreshLeeway
+}
@@ -0,0 +1,236 @@
+package tango
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/c150pilot/go-tango-card/internal/jsonfile"
+)
+
+// DefaultTransferRetention is how long a completed transfer's TransferID is
+// remembered when the client has no TransferRetention set.
+const DefaultTransferRetention = 30 * 24 * time.Hour
+
+// TransferState is where a transfer with a TransferID stands.
+type TransferState string
+
+const (
+	// TransferPending is stored before the transfer is sent. A pending record
+	// left behind by a crash means the outcome is unknown.
+	TransferPending TransferState = "pending"
+	// TransferSucceeded means the funds moved; Transfer holds the record.
+	TransferSucceeded TransferState = "succeeded"
+	// TransferUnknown means the request was sent but its result is not known,
+	// for example after a network error or a server error.
+	TransferUnknown TransferState = "unknown"
+)
+
+// TransferRecord is what a TransferStore keeps for one TransferID.
+type TransferRecord struct {
+	TransferID string        `json:"transferID"`
+	State      TransferState `json:"state"`
+	Transfer   FundTransfer  `json:"transfer"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+}
+
+/*
+TransferStore keeps the records behind TransferFundsRequest.TransferID.
+TransferFunds reserves an ID with a pending record before sending the
+transfer, and replaces or deletes the record once it has a response.
+
+Reserve must be atomic, and no method may return until its change is
+durable. Prune is called before each reservation; records that are pending
+or unknown must never be pruned, since dropping one would let a retry move
+the funds again.
+*/
+type TransferStore interface {
+	// Reserve stores record if its TransferID has none and reports true.
+	// Otherwise it returns the existing record and false.
+	Reserve(record TransferRecord) (TransferRecord, bool, error)
+	// Get returns the record for transferID, and false if there is none.
+	Get(transferID string) (TransferRecord, bool, error)
+	// Put replaces the record with the same TransferID.
+	Put(record TransferRecord) error
+	// Delete removes the record for transferID, if any.
+	Delete(transferID string) error
+	// Prune removes succeeded records last updated before cutoff.
+	Prune(cutoff time.Time) error
+}
+
+// MemoryTransferStore is a TransferStore that keeps records in memory only.
+// It is what a client uses when no TransferStore is set.
+type MemoryTransferStore struct {
+	mu      sync.Mutex
+	records map[string]TransferRecord
+}
+
+// NewMemoryTransferStore returns an empty MemoryTransferStore.
+func NewMemoryTransferStore() *MemoryTransferStore {
+	return &MemoryTransferStore{records: make(map[string]TransferRecord)}
+}
+
+func (s *MemoryTransferStore) Reserve(record TransferRecord) (TransferRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[record.TransferID]; ok {
+		return existing, false, nil
+	}
+	s.records[record.TransferID] = record
+	return record, true, nil
+}
+
+func (s *MemoryTransferStore) Get(transferID string) (TransferRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[transferID]
+	return record, ok, nil
+}
+
+func (s *MemoryTransferStore) Put(record TransferRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.records[record.TransferID] = record
+	return nil
+}
+
+func (s *MemoryTransferStore) Delete(transferID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, transferID)
+	return nil
+}
+
+func (s *MemoryTransferStore) Prune(cutoff time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune(cutoff)
+	return nil
+}
+
+// prune removes expired records and reports whether any were removed. The
+// caller must hold s.mu.
+func (s *MemoryTransferStore) prune(cutoff time.Time) bool {
+	pruned := false
+	for id, record := range s.records {
+		if record.State == TransferSucceeded && record.UpdatedAt.Before(cutoff) {
+			delete(s.records, id)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+// FileTransferStore is a TransferStore kept in a single JSON file, rewritten
+// through a temporary file and a rename on every change.
+type FileTransferStore struct {
+	path   string
+	memory *MemoryTransferStore
+}
+
+// OpenFileTransferStore opens the records stored at path, creating the file
+// on the first change if it does not exist.
+func OpenFileTransferStore(path string) (*FileTransferStore, error) {
+	s := &FileTransferStore{path: path, memory: NewMemoryTransferStore()}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var records []TransferRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("transfer file %s is corrupt: %w", path, err)
+	}
+	for _, record := range records {
+		s.memory.records[record.TransferID] = record
+	}
+	return s, nil
+}
+
+func (s *FileTransferStore) Reserve(record TransferRecord) (TransferRecord, bool, error) {
+	s.memory.mu.Lock()
+	defer s.memory.mu.Unlock()
+
+	if existing, ok := s.memory.records[record.TransferID]; ok {
+		return existing, false, nil
+	}
+	s.memory.records[record.TransferID] = record
+	if err := s.save(); err != nil {
+		delete(s.memory.records, record.TransferID)
+		return TransferRecord{}, false, err
+	}
+	return record, true, nil
+}
+
+func (s *FileTransferStore) Get(transferID string) (TransferRecord, bool, error) {
+	return s.memory.Get(transferID)
+}
+
+func (s *FileTransferStore) Put(record TransferRecord) error {
+	s.memory.mu.Lock()
+	defer s.memory.mu.Unlock()
+
+	previous, existed := s.memory.records[record.TransferID]
+	s.memory.records[record.TransferID] = record
+	if err := s.save(); err != nil {
+		if existed {
+			s.memory.records[record.TransferID] = previous
+		} else {
+			delete(s.memory.records, record.TransferID)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *FileTransferStore) Delete(transferID string) error {
+	s.memory.mu.Lock()
+	defer s.memory.mu.Unlock()
+
+	previous, existed := s.memory.records[transferID]
+	if !existed {
+		return nil
+	}
+	delete(s.memory.records, transferID)
+	if err := s.save(); err != nil {
+		s.memory.records[transferID] = previous
+		return err
+	}
+	return nil
+}
+
+func (s *FileTransferStore) Prune(cutoff time.Time) error {
+	s.memory.mu.Lock()
+	defer s.memory.mu.Unlock()
+
+	if !s.memory.prune(cutoff) {
+		return nil
+	}
+	// If the write fails, the file keeps the old records until the next change
+	return s.save()
+}
+
+// save writes every record to the file. The caller must hold s.memory.mu.
+func (s *FileTransferStore) save() error {
+	records := make([]TransferRecord, 0, len(s.memory.records))
+	for _, record := range s.memory.records {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].TransferID < records[j].TransferID
+	})
+	return jsonfile.Write(s.path, records)
+}
@@ -0,0 +1,230 @@
+package tango
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+type TransferFundsRequest struct {
+	CustomerIdentifier           string  `json:"customerIdentifier"`
+	SourceAccountIdentifier      string  `json:"sourceAccountIdentifier"`
+	DestinationAccountIdentifier string  `json:"destinationAccountIdentifier"`
+	Amount                       float64 `json:"amount"`
+	Notes                        string  `json:"notes,omitempty"`
+
+	// TransferID, when set, keeps a retry from moving funds twice: a second
+	// transfer with the same ID returns the first one's record, or
+	// ErrTransferOutcomeUnknown if it is not known whether the first one went
+	// through. It is not sent to Tango.
+	//
+	// The IDs are recorded in the client's TransferStore. Without one they are
+	// kept in memory and lost when the process restarts or a
+	// ClientManager.Reload replaces the client, so set a TransferStore such as
+	// a FileTransferStore to keep retries safe across those. Completed IDs are
+	// forgotten after TransferRetention.
+	TransferID string `json:"-"`
+}
+
+// FundTransfer is the record of a transfer between two accounts.
+type FundTransfer struct {
+	TransferID                   string  `json:"transferID"`
+	CustomerIdentifier           string  `json:"customerIdentifier"`
+	SourceAccountIdentifier      string  `json:"sourceAccountIdentifier"`
+	DestinationAccountIdentifier string  `json:"destinationAccountIdentifier"`
+	Amount                       float64 `json:"amount"`
+	CurrencyCode                 string  `json:"currencyCode"`
+	Status                       string  `json:"status"`
+	CreatedAt                    string  `json:"createdAt"`
+}
+
+var (
+	// ErrTransferInProgress is returned when a transfer is retried while the
+	// first attempt with the same TransferID has not finished yet, or while a
+	// pending record left by a crash awaits ResolveTransfer.
+	ErrTransferInProgress = errors.New("transfer already in progress")
+	// ErrTransferOutcomeUnknown is returned for a TransferID whose earlier
+	// attempt failed in a way that leaves open whether the funds moved, such
+	// as a dropped connection or a server error. Check the account balances
+	// and call ResolveTransfer before using the ID again.
+	ErrTransferOutcomeUnknown = errors.New("transfer outcome unknown")
+)
+
+/*
+Move funds between two accounts of the same customer.
+https://developers.tangocard.com/reference/transferfunds
+
+Both accounts are looked up first, and the transfer is refused unless they
+exist and hold the same currency. See TransferFundsRequest.TransferID for
+retrying safely. A transfer that fails without a definite answer from Tango, a
+network error or a temporary APIError, may or may not have gone through; its
+TransferID is held until ResolveTransfer says which.
+*/
+func (c *TangoClient) TransferFunds(data TransferFundsRequest) (FundTransfer, error) {
+	if data.CustomerIdentifier == "" {
+		return FundTransfer{}, fmt.Errorf("customerIdentifier is required")
+	}
+	if data.SourceAccountIdentifier == "" || data.DestinationAccountIdentifier == "" {
+		return FundTransfer{}, fmt.Errorf("source and destination accounts are required")
+	}
+	if data.SourceAccountIdentifier == data.DestinationAccountIdentifier {
+		return FundTransfer{}, fmt.Errorf("source and destination accounts must differ")
+	}
+	if data.Amount <= 0 {
+		return FundTransfer{}, fmt.Errorf("amount must be greater than zero")
+	}
+
+	if data.TransferID != "" {
+		transfer, done, err := c.reserveTransfer(data.TransferID)
+		if err != nil || done {
+			return transfer, err
+		}
+	}
+
+	transfer, ambiguous, err := c.transferFunds(data)
+
+	if data.TransferID != "" {
+		if storeErr := c.finishTransfer(data.TransferID, transfer, ambiguous, err); storeErr != nil && err == nil {
+			// The ID stays pending, so a retry is held back rather than sent again
+			return transfer, fmt.Errorf("transfer %s went through but recording it failed: %w", data.TransferID, storeErr)
+		}
+	}
+	return transfer, err
+}
+
+/*
+ResolveTransfer settles a TransferID left in doubt by a failed TransferFunds,
+once the account balances show whether the funds moved. If moved is true, the
+ID is recorded with transfer and later calls with it return that record;
+otherwise the ID is released so the transfer can be sent again. A pending ID
+left behind by a crash is resolved the same way; do not resolve an ID whose
+transfer is still running.
+
+It returns an error if the ID is not awaiting resolution.
+*/
+func (c *TangoClient) ResolveTransfer(transferID string, moved bool, transfer FundTransfer) error {
+	store := c.transferStore()
+	record, ok, err := store.Get(transferID)
+	if err != nil {
+		return err
+	}
+	if !ok || record.State == TransferSucceeded {
+		return fmt.Errorf("transfer %q is not awaiting resolution", transferID)
+	}
+	if !moved {
+		return store.Delete(transferID)
+	}
+	if transfer.TransferID == "" {
+		transfer.TransferID = transferID
+	}
+	return store.Put(TransferRecord{TransferID: transferID, State: TransferSucceeded, Transfer: transfer, UpdatedAt: timeNow()})
+}
+
+// transferFunds runs the checks and the transfer. ambiguous reports an error
+// after which the funds may have moved.
+func (c *TangoClient) transferFunds(data TransferFundsRequest) (transfer FundTransfer, ambiguous bool, err error) {
+	source, err := c.GetAccountInfo(data.SourceAccountIdentifier)
+	if err != nil {
+		return FundTransfer{}, false, fmt.Errorf("source account %q: %w", data.SourceAccountIdentifier, err)
+	}
+	destination, err := c.GetAccountInfo(data.DestinationAccountIdentifier)
+	if err != nil {
+		return FundTransfer{}, false, fmt.Errorf("destination account %q: %w", data.DestinationAccountIdentifier, err)
+	}
+	if source.CurrencyCode != destination.CurrencyCode {
+		return FundTransfer{}, false, fmt.Errorf("cannot transfer between accounts in different currencies (%s and %s)", source.CurrencyCode, destination.CurrencyCode)
+	}
+
+	url := c.apiURL() + "/transferFunds"
+
+	payloadJSON, err := json.Marshal(data)
+	if err != nil {
+		return FundTransfer{}, false, err
+	}
+
+	client := c.newHTTPClient()
+
+	resp, err := client.R().
+		SetHeader("Content-Type", "application/json").
+		SetBody(payloadJSON).
+		Post(url)
+	if err != nil {
+		return FundTransfer{}, true, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if err := ensureSuccessStatus(resp, "transfer funds"); err != nil {
+		var apiErr *APIError
+		temporary := errors.As(err, &apiErr) && apiErr.Temporary()
+		return FundTransfer{}, temporary, withErrorDetails(err)
+	}
+
+	// Tango accepted the transfer, so an unreadable response leaves its record unknown
+	var responseData FundTransfer
+	err = json.Unmarshal(resp.Body(), &responseData)
+	if err != nil {
+		return FundTransfer{}, true, err
+	}
+
+	if responseData.TransferID == "" {
+		responseData.TransferID = data.TransferID
+	}
+	if responseData.CurrencyCode == "" {
+		responseData.CurrencyCode = source.CurrencyCode
+	}
+	return responseData, false, nil
+}
+
+func (c *TangoClient) transferStore() TransferStore {
+	if c.TransferStore != nil {
+		return c.TransferStore
+	}
+	return c.state().transfers
+}
+
+// reserveTransfer marks transferID as pending, first pruning expired records.
+// It reports done with the earlier record if a transfer with the same ID
+// already succeeded, and fails if one is in progress or its outcome is
+// unknown.
+func (c *TangoClient) reserveTransfer(transferID string) (FundTransfer, bool, error) {
+	store := c.transferStore()
+	retention := c.TransferRetention
+	if retention <= 0 {
+		retention = DefaultTransferRetention
+	}
+	now := timeNow()
+	if err := store.Prune(now.Add(-retention)); err != nil {
+		return FundTransfer{}, false, fmt.Errorf("pruning transfer records: %w", err)
+	}
+
+	record, reserved, err := store.Reserve(TransferRecord{TransferID: transferID, State: TransferPending, UpdatedAt: now})
+	if err != nil {
+		return FundTransfer{}, false, fmt.Errorf("reserving transfer %s: %w", transferID, err)
+	}
+	if reserved {
+		return FundTransfer{}, false, nil
+	}
+	switch record.State {
+	case TransferSucceeded:
+		return record.Transfer, true, nil
+	case TransferPending:
+		return FundTransfer{}, false, fmt.Errorf("%w: %s", ErrTransferInProgress, transferID)
+	default:
+		return FundTransfer{}, false, fmt.Errorf("%w: %s", ErrTransferOutcomeUnknown, transferID)
+	}
+}
+
+// finishTransfer releases transferID after a definite failure, records the
+// transfer if it succeeded, and marks the ID unknown otherwise.
+func (c *TangoClient) finishTransfer(transferID string, transfer FundTransfer, ambiguous bool, err error) error {
+	store := c.transferStore()
+	record := TransferRecord{TransferID: transferID, UpdatedAt: timeNow()}
+	switch {
+	case err == nil:
+		record.State = TransferSucceeded
+		record.Transfer = transfer
+	case ambiguous:
+		record.State = TransferUnknown
+	default:
+		return store.Delete(transferID)
+	}
+	return store.Put(record)
+}
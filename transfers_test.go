@@ -0,0 +1,266 @@
+package tango
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func transferServer(t *testing.T, transfers *int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /accounts/marketing":
+			_, _ = w.Write([]byte(`{"accountIdentifier":"marketing","currencyCode":"USD"}`))
+		case "GET /accounts/sales":
+			_, _ = w.Write([]byte(`{"accountIdentifier":"sales","currencyCode":"USD"}`))
+		case "GET /accounts/europe":
+			_, _ = w.Write([]byte(`{"accountIdentifier":"europe","currencyCode":"EUR"}`))
+		case "POST /transferFunds":
+			*transfers++
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if _, ok := body["TransferID"]; ok {
+				t.Errorf("transfer ID should not be sent: %v", body)
+			}
+			_, _ = w.Write([]byte(`{"customerIdentifier":"cust","sourceAccountIdentifier":"marketing","destinationAccountIdentifier":"sales","amount":250,"status":"SUCCESS"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func TestTransferFunds(t *testing.T) {
+	var transfers int
+	newTestServer(t, transferServer(t, &transfers))
+	client := &TangoClient{Token: "token"}
+
+	request := TransferFundsRequest{
+		CustomerIdentifier:           "cust",
+		SourceAccountIdentifier:      "marketing",
+		DestinationAccountIdentifier: "sales",
+		Amount:                       250,
+		TransferID:                   "2024-06-rebalance",
+	}
+	transfer, err := client.TransferFunds(request)
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if transfer.TransferID != "2024-06-rebalance" || transfer.CurrencyCode != "USD" || transfer.Amount != 250 {
+		t.Fatalf("unexpected transfer: %+v", transfer)
+	}
+
+	again, err := client.TransferFunds(request)
+	if err != nil || again != transfer {
+		t.Fatalf("expected the retry to return the first transfer, got %+v, %v", again, err)
+	}
+	if transfers != 1 {
+		t.Fatalf("expected funds to move once, got %d transfers", transfers)
+	}
+
+	request.TransferID = ""
+	if _, err := client.TransferFunds(request); err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+	if transfers != 2 {
+		t.Fatalf("expected a transfer without an ID to always be sent, got %d", transfers)
+	}
+}
+
+func TestTransferFunds_PreChecks(t *testing.T) {
+	var transfers int
+	newTestServer(t, transferServer(t, &transfers))
+	client := &TangoClient{Token: "token"}
+
+	tests := []struct {
+		name        string
+		source      string
+		destination string
+		want        string
+	}{
+		{"currency mismatch", "marketing", "europe", "different currencies"},
+		{"missing account", "marketing", "nowhere", "destination account"},
+		{"same account", "sales", "sales", "must differ"},
+	}
+	for _, tt := range tests {
+		_, err := client.TransferFunds(TransferFundsRequest{
+			CustomerIdentifier:           "cust",
+			SourceAccountIdentifier:      tt.source,
+			DestinationAccountIdentifier: tt.destination,
+			Amount:                       10,
+			TransferID:                   tt.name,
+		})
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Fatalf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+	if transfers != 0 {
+		t.Fatalf("expected no transfers, got %d", transfers)
+	}
+
+	// A failed transfer can be retried under the same ID
+	_, err := client.TransferFunds(TransferFundsRequest{
+		CustomerIdentifier:           "cust",
+		SourceAccountIdentifier:      "marketing",
+		DestinationAccountIdentifier: "sales",
+		Amount:                       10,
+		TransferID:                   "currency mismatch",
+	})
+	if err != nil || transfers != 1 {
+		t.Fatalf("expected the retry to go through, got %v after %d transfers", err, transfers)
+	}
+}
+
+func TestTransferFunds_OutcomeUnknown(t *testing.T) {
+	var mu sync.Mutex
+	var posts int
+	var respond func(w http.ResponseWriter)
+	setResponse := func(f func(w http.ResponseWriter)) {
+		mu.Lock()
+		defer mu.Unlock()
+		respond = f
+	}
+	postCount := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return posts
+	}
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`{"currencyCode":"USD"}`))
+			return
+		}
+		mu.Lock()
+		posts++
+		f := respond
+		mu.Unlock()
+		f(w)
+	})
+	client := &TangoClient{Token: "token"}
+	request := TransferFundsRequest{
+		CustomerIdentifier:           "cust",
+		SourceAccountIdentifier:      "marketing",
+		DestinationAccountIdentifier: "sales",
+		Amount:                       250,
+		TransferID:                   "june",
+	}
+
+	// The connection drops after Tango received the transfer
+	setResponse(func(w http.ResponseWriter) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("hijack: %v", err)
+			return
+		}
+		conn.Close()
+	})
+	if _, err := client.TransferFunds(request); err == nil {
+		t.Fatal("expected the dropped connection to fail")
+	}
+	if _, err := client.TransferFunds(request); !errors.Is(err, ErrTransferOutcomeUnknown) {
+		t.Fatalf("expected ErrTransferOutcomeUnknown, got %v", err)
+	}
+	if postCount() != 1 {
+		t.Fatalf("expected the retry to be held back, got %d posts", postCount())
+	}
+
+	// Balances show the funds did not move, so the ID is released
+	if err := client.ResolveTransfer("june", false, FundTransfer{}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	setResponse(func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) })
+	if _, err := client.TransferFunds(request); err == nil {
+		t.Fatal("expected the server error to fail")
+	}
+	if _, err := client.TransferFunds(request); !errors.Is(err, ErrTransferOutcomeUnknown) {
+		t.Fatalf("expected a server error to leave the outcome unknown, got %v", err)
+	}
+
+	// Balances show the funds moved, so retries return the record
+	if err := client.ResolveTransfer("june", true, FundTransfer{Amount: 250, Status: "SUCCESS"}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+	transfer, err := client.TransferFunds(request)
+	if err != nil || transfer.TransferID != "june" || transfer.Status != "SUCCESS" || postCount() != 2 {
+		t.Fatalf("expected the resolved record without another post, got %+v, %v after %d posts", transfer, err, postCount())
+	}
+	if err := client.ResolveTransfer("june", false, FundTransfer{}); err == nil {
+		t.Fatal("expected an error resolving a transfer that is not in doubt")
+	}
+
+	// A definite rejection frees the ID straight away
+	request.TransferID = "july"
+	setResponse(func(w http.ResponseWriter) { w.WriteHeader(http.StatusBadRequest) })
+	if _, err := client.TransferFunds(request); err == nil {
+		t.Fatal("expected the rejection to fail")
+	}
+	setResponse(func(w http.ResponseWriter) { _, _ = w.Write([]byte(`{"status":"SUCCESS"}`)) })
+	if _, err := client.TransferFunds(request); err != nil || postCount() != 4 {
+		t.Fatalf("expected the rejected transfer to be retried, got %v after %d posts", err, postCount())
+	}
+}
+
+func TestTransferFunds_FileStore(t *testing.T) {
+	var transfers int
+	newTestServer(t, transferServer(t, &transfers))
+	path := filepath.Join(t.TempDir(), "transfers.json")
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	originalNow := timeNow
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = originalNow }()
+
+	open := func() *TangoClient {
+		t.Helper()
+		store, err := OpenFileTransferStore(path)
+		if err != nil {
+			t.Fatalf("open store: %v", err)
+		}
+		return &TangoClient{Token: "token", TransferStore: store}
+	}
+	request := TransferFundsRequest{
+		CustomerIdentifier:           "cust",
+		SourceAccountIdentifier:      "marketing",
+		DestinationAccountIdentifier: "sales",
+		Amount:                       250,
+		TransferID:                   "june",
+	}
+
+	first, err := open().TransferFunds(request)
+	if err != nil {
+		t.Fatalf("transfer: %v", err)
+	}
+
+	// A new client on the same file, as after a restart, still knows the ID
+	client := open()
+	again, err := client.TransferFunds(request)
+	if err != nil || again != first || transfers != 1 {
+		t.Fatalf("expected the retry to return the first transfer, got %+v, %v after %d transfers", again, err, transfers)
+	}
+
+	// A pending record left by a crash holds the ID until it is resolved
+	if err := client.TransferStore.Put(TransferRecord{TransferID: "july", State: TransferPending, UpdatedAt: now}); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	request.TransferID = "july"
+	if _, err := open().TransferFunds(request); !errors.Is(err, ErrTransferInProgress) {
+		t.Fatalf("expected the pending ID to be held, got %v", err)
+	}
+	if err := open().ResolveTransfer("july", false, FundTransfer{}); err != nil {
+		t.Fatalf("resolve: %v", err)
+	}
+
+	// Completed IDs are forgotten after the retention period
+	now = now.Add(DefaultTransferRetention + time.Hour)
+	request.TransferID = "june"
+	if _, err := open().TransferFunds(request); err != nil || transfers != 2 {
+		t.Fatalf("expected the expired ID to be sent again, got %v after %d transfers", err, transfers)
+	}
+	records := open().TransferStore.(*FileTransferStore).memory.records
+	if len(records) != 1 || !records["june"].UpdatedAt.Equal(now) {
+		t.Fatalf("expected only the new june record, got %+v", records)
+	}
+}
@@ -0,0 +1,229 @@
+package tango
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// WaitOptions controls how WaitForOrder and WaitForLineItem poll. Zero values
+// use the defaults noted on each field.
+type WaitOptions struct {
+	// InitialInterval is the delay before the second poll. Defaults to 2s.
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between polls. Defaults to 30s.
+	MaxInterval time.Duration
+	// Multiplier grows the delay after each poll. Defaults to 2.
+	Multiplier float64
+	// Timeout bounds the whole wait. Defaults to 10 minutes; the context
+	// deadline applies as well.
+	Timeout time.Duration
+}
+
+// WaitState is the reason a wait ended without the reward being delivered.
+type WaitState string
+
+const (
+	WaitStateFailed    WaitState = "failed"
+	WaitStateCancelled WaitState = "cancelled"
+	WaitStateTimeout   WaitState = "timeout"
+)
+
+/*
+WaitError is returned by WaitForOrder and WaitForLineItem when the order or line
+item ends in a failed or cancelled state, or is still pending when the wait
+times out. Status holds the last status seen.
+
+For timeouts caused by the context, Err is the context's error, so
+errors.Is(err, context.Canceled) works as expected.
+*/
+type WaitError struct {
+	State  WaitState
+	ID     string
+	Status string
+	Err    error
+}
+
+func (e *WaitError) Error() string {
+	status := e.Status
+	if status == "" {
+		status = "unknown"
+	}
+	if e.State == WaitStateTimeout {
+		return fmt.Sprintf("timed out waiting for %s (last status %s)", e.ID, status)
+	}
+	return fmt.Sprintf("%s ended %s (status %s)", e.ID, e.State, status)
+}
+
+func (e *WaitError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	failedStatuses    = []string{"FAILED", "DECLINED", "REJECTED", "ERROR"}
+	cancelledStatuses = []string{"CANCELLED", "CANCELED", "VOIDED"}
+	// emailPendingStatuses are delivery states that may still change
+	emailPendingStatuses = []string{"PENDING", "QUEUED", "PROCESSING", "SENDING", "SCHEDULED"}
+	emailFailedStatuses  = []string{"FAILED", "BOUNCED", "UNDELIVERABLE", "REJECTED", "DROPPED"}
+)
+
+/*
+WaitForOrder polls GetOrder with exponential backoff until the order reaches a
+terminal status and returns the final order.
+
+A COMPLETE order is returned with a nil error. Failed and cancelled orders, and
+orders still pending at the timeout, return the last order seen with a
+*WaitError. Rate limiting, server errors and network errors while polling are
+retried; other API errors end the wait immediately.
+*/
+func (c *TangoClient) WaitForOrder(ctx context.Context, referenceOrderID string, opts WaitOptions) (CreateOrderResponse, error) {
+	var last CreateOrderResponse
+	err := poll(ctx, referenceOrderID, opts, func(ctx context.Context) (string, WaitState, bool, error) {
+		order, err := c.getOrder(ctx, referenceOrderID)
+		if err != nil {
+			return last.Status, "", false, err
+		}
+		last = order
+		state, done := orderWaitState(order.Status)
+		return order.Status, state, done, nil
+	})
+	return last, err
+}
+
+/*
+WaitForLineItem polls GetLineItem with exponential backoff until the line item
+is delivered or has failed, and returns the final line item.
+
+A line item is delivered when its Status is COMPLETE and its EmailStatus, if
+any, is no longer pending. A failed or cancelled Status, or a failed
+EmailStatus such as BOUNCED, returns a *WaitError, as does a timeout.
+*/
+func (c *TangoClient) WaitForLineItem(ctx context.Context, lineItemID string, opts WaitOptions) (LineItem, error) {
+	var last LineItem
+	err := poll(ctx, lineItemID, opts, func(ctx context.Context) (string, WaitState, bool, error) {
+		lineItem, err := c.getLineItem(ctx, lineItemID)
+		if err != nil {
+			return lineItemStatus(last), "", false, err
+		}
+		last = lineItem
+		state, done := lineItemWaitState(lineItem)
+		return lineItemStatus(lineItem), state, done, nil
+	})
+	return last, err
+}
+
+func orderWaitState(status string) (WaitState, bool) {
+	switch {
+	case strings.EqualFold(status, "COMPLETE"):
+		return "", true
+	case hasStatus(failedStatuses, status):
+		return WaitStateFailed, true
+	case hasStatus(cancelledStatuses, status):
+		return WaitStateCancelled, true
+	default:
+		return "", false
+	}
+}
+
+func lineItemWaitState(lineItem LineItem) (WaitState, bool) {
+	state, done := orderWaitState(lineItem.Status)
+	if !done || state != "" {
+		return state, done
+	}
+	switch {
+	case hasStatus(emailFailedStatuses, lineItem.EmailStatus):
+		return WaitStateFailed, true
+	case hasStatus(emailPendingStatuses, lineItem.EmailStatus):
+		return "", false
+	default:
+		return "", true
+	}
+}
+
+func lineItemStatus(lineItem LineItem) string {
+	if lineItem.EmailStatus == "" {
+		return lineItem.Status
+	}
+	return lineItem.Status + "/" + lineItem.EmailStatus
+}
+
+func hasStatus(statuses []string, status string) bool {
+	for _, candidate := range statuses {
+		if strings.EqualFold(candidate, status) {
+			return true
+		}
+	}
+	return false
+}
+
+// poll calls check until it reports done, backing off between calls. check is
+// given the wait's context so a request in progress is abandoned at the deadline.
+func poll(ctx context.Context, id string, opts WaitOptions, check func(ctx context.Context) (status string, state WaitState, done bool, err error)) error {
+	if id == "" {
+		return fmt.Errorf("id is required")
+	}
+
+	interval := opts.InitialInterval
+	if interval <= 0 {
+		interval = 2 * time.Second
+	}
+	maxInterval := opts.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = 30 * time.Second
+	}
+	multiplier := opts.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Minute
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		status, state, done, err := check(ctx)
+		// A final status counts even if it arrived as the deadline passed
+		if err == nil && done {
+			if state == "" {
+				return nil
+			}
+			return &WaitError{State: state, ID: id, Status: status}
+		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return &WaitError{State: WaitStateTimeout, ID: id, Status: status, Err: ctxErr}
+		}
+		if err != nil && !isTransient(err) {
+			return err
+		}
+
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &WaitError{State: WaitStateTimeout, ID: id, Status: status, Err: ctx.Err()}
+		case <-timer.C:
+		}
+
+		interval = time.Duration(float64(interval) * multiplier)
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// isTransient reports whether err is worth retrying: rate limiting, server
+// errors and failures to reach the API at all.
+func isTransient(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,154 @@
+package tango
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fastWait = WaitOptions{InitialInterval: time.Millisecond, MaxInterval: 2 * time.Millisecond, Timeout: time.Second}
+
+func TestWaitForOrder(t *testing.T) {
+	var polls int32
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&polls, 1) {
+		case 1:
+			_, _ = w.Write([]byte(`{"referenceOrderID":"RA1","status":"PENDING"}`))
+		case 2:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`{"referenceOrderID":"RA1","status":"COMPLETE"}`))
+		}
+	})
+
+	client := &TangoClient{Token: "token"}
+	order, err := client.WaitForOrder(context.Background(), "RA1", fastWait)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if order.Status != "COMPLETE" || atomic.LoadInt32(&polls) != 3 {
+		t.Fatalf("unexpected result %+v after %d polls", order, polls)
+	}
+}
+
+func TestWaitForOrder_TerminalStates(t *testing.T) {
+	for status, want := range map[string]WaitState{"FAILED": WaitStateFailed, "CANCELLED": WaitStateCancelled} {
+		status := status
+		newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"referenceOrderID":"RA1","status":"` + status + `"}`))
+		})
+
+		client := &TangoClient{Token: "token"}
+		order, err := client.WaitForOrder(context.Background(), "RA1", fastWait)
+		var waitErr *WaitError
+		if !errors.As(err, &waitErr) || waitErr.State != want || waitErr.Status != status {
+			t.Fatalf("expected %s wait error, got %v", want, err)
+		}
+		if order.Status != status {
+			t.Fatalf("expected final order to be returned, got %+v", order)
+		}
+	}
+}
+
+func TestWaitForOrder_Timeout(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"referenceOrderID":"RA1","status":"PENDING"}`))
+	})
+
+	client := &TangoClient{Token: "token"}
+	opts := fastWait
+	opts.Timeout = 20 * time.Millisecond
+	_, err := client.WaitForOrder(context.Background(), "RA1", opts)
+	var waitErr *WaitError
+	if !errors.As(err, &waitErr) || waitErr.State != WaitStateTimeout || waitErr.Status != "PENDING" {
+		t.Fatalf("expected timeout wait error, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded cause, got %v", err)
+	}
+}
+
+func TestWait_HangingServer(t *testing.T) {
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	})
+	client := &TangoClient{Token: "token"}
+
+	opts := fastWait
+	opts.Timeout = 100 * time.Millisecond
+	start := time.Now()
+	_, err := client.WaitForOrder(context.Background(), "RA1", opts)
+	var waitErr *WaitError
+	if !errors.As(err, &waitErr) || waitErr.State != WaitStateTimeout || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the request to be abandoned at the timeout, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, err = client.WaitForLineItem(ctx, "RA1-01", fastWait)
+	if !errors.As(err, &waitErr) || waitErr.State != WaitStateTimeout {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the request to follow the context, took %v", elapsed)
+	}
+}
+
+func TestWait_FinishedAtDeadline(t *testing.T) {
+	opts := fastWait
+	opts.Timeout = 20 * time.Millisecond
+	err := poll(context.Background(), "RA1", opts, func(ctx context.Context) (string, WaitState, bool, error) {
+		// The order finishes just as the deadline passes
+		<-ctx.Done()
+		return "COMPLETE", "", true, nil
+	})
+	if err != nil {
+		t.Fatalf("expected the final status to win over the deadline, got %v", err)
+	}
+}
+
+func TestWaitForOrder_PermanentError(t *testing.T) {
+	var polls int32
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&polls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	client := &TangoClient{Token: "token"}
+	_, err := client.WaitForOrder(context.Background(), "RA1", fastWait)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || polls != 1 {
+		t.Fatalf("expected immediate 404 APIError, got %v after %d polls", err, polls)
+	}
+}
+
+func TestWaitForLineItem(t *testing.T) {
+	var polls int32
+	newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&polls, 1) == 1 {
+			_, _ = w.Write([]byte(`{"referenceLineItemID":"L1","status":"COMPLETE","emailStatus":"QUEUED"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"referenceLineItemID":"L1","status":"COMPLETE","emailStatus":"BOUNCED"}`))
+	})
+
+	client := &TangoClient{Token: "token"}
+	lineItem, err := client.WaitForLineItem(context.Background(), "L1", fastWait)
+	var waitErr *WaitError
+	if !errors.As(err, &waitErr) || waitErr.State != WaitStateFailed {
+		t.Fatalf("expected failed wait error for bounced email, got %v", err)
+	}
+	if lineItem.EmailStatus != "BOUNCED" {
+		t.Fatalf("expected final line item, got %+v", lineItem)
+	}
+}